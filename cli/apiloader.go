@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -30,39 +31,104 @@ func LoadAPIList() (types.APIList, error) {
 
 // GetAPIInfo finds API information from tree-structured api_list
 func GetAPIInfo(apiList types.APIList, nf, apiName string) (*types.APIListEntry, error) {
-	if nfServices, exists := apiList[nf]; exists {
-		for _, serviceInfo := range nfServices {
-			if api, exists := serviceInfo.APIs[apiName]; exists {
-				return &api, nil
+	resolved, err := ResolveAPI(apiList, nf, "", apiName)
+	if err != nil {
+		return nil, err
+	}
+	return &resolved.Entry, nil
+}
+
+// ResolvedAPI is an API entry together with the service it belongs to
+type ResolvedAPI struct {
+	Service     string
+	Name        string
+	ServiceInfo types.ServiceAPIList
+	Entry       types.APIListEntry
+}
+
+// ResolveAPI finds the service and API entry addressed by apiName within an NF.
+// apiName may be the API name, the API name with a " [METHOD]" suffix, or the
+// unique key recorded in api_list.yaml. serviceName narrows the search to one service.
+func ResolveAPI(apiList types.APIList, nf, serviceName, apiName string) (*ResolvedAPI, error) {
+	nfServices, exists := apiList[nf]
+	if !exists {
+		return nil, fmt.Errorf("NF '%s' not found in api_list.yaml", nf)
+	}
+
+	type match struct {
+		service string
+		name    string
+	}
+	var matches []match
+
+	cleanName := CleanAPIName(apiName)
+	method := ""
+	if cleanName != apiName {
+		method = ExtractMethodFromAPIName(apiName)
+	}
+
+	for _, svcName := range getSortedKeys(nfServices) {
+		if serviceName != "" && !strings.EqualFold(svcName, serviceName) {
+			continue
+		}
+
+		for _, name := range getSortedKeys(nfServices[svcName].APIs) {
+			api := nfServices[svcName].APIs[name]
+			switch {
+			case api.Key == apiName, name == apiName:
+			case name == cleanName && api.Method == method:
+			default:
+				continue
 			}
+			matches = append(matches, match{service: svcName, name: name})
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if serviceName != "" {
+			return nil, fmt.Errorf("API '%s' not found in service '%s' of NF '%s'", apiName, serviceName, nf)
 		}
+		return nil, fmt.Errorf("API '%s' not found in NF '%s'", apiName, nf)
+	case 1:
+		serviceInfo := nfServices[matches[0].service]
+		return &ResolvedAPI{
+			Service:     matches[0].service,
+			Name:        matches[0].name,
+			ServiceInfo: serviceInfo,
+			Entry:       serviceInfo.APIs[matches[0].name],
+		}, nil
+	}
+
+	var candidates []string
+	for _, m := range matches {
+		candidates = append(candidates, fmt.Sprintf("%q (-s %q)", nfServices[m.service].APIs[m.name].Key, m.service))
 	}
-	return nil, fmt.Errorf("API '%s' not found in NF '%s'", apiName, nf)
+	return nil, fmt.Errorf("API '%s' is ambiguous in NF '%s', use -s SERVICE or the unique key: %s",
+		apiName, nf, strings.Join(candidates, ", "))
 }
 
 // PrepareAPIExecution prepares API execution info from api_list and configuration
-func PrepareAPIExecution(apiList types.APIList, config map[string]interface{}, nf, apiName string) (*types.APIExecutionInfo, error) {
+func PrepareAPIExecution(apiList types.APIList, config *types.ConfigurationFile, nf, serviceName, apiName string) (*types.APIExecutionInfo, error) {
 	fmt.Printf("🔍 DEBUG: Starting PrepareAPIExecution for NF=%s, API=%s\n", nf, apiName)
 
-	apiInfo, err := GetAPIInfo(apiList, nf, apiName)
+	resolved, err := ResolveAPI(apiList, nf, serviceName, apiName)
 	if err != nil {
 		return nil, err
 	}
+	apiInfo := &resolved.Entry
 
 	fmt.Printf("🔍 DEBUG: Found API info - Parameters count: %d\n", len(apiInfo.Parameters))
 	for i, p := range apiInfo.Parameters {
 		fmt.Printf("🔍 DEBUG: Parameter[%d]: Name=%s, Required=%t, Type=%s\n", i, p.Name, p.Required, p.Type)
 	}
 
-	userInputs, ok := config["user_inputs"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("user_inputs not found in configuration")
-	}
+	userInputs := config.UserInputs
 
 	// Prepare parameters - both required and optional
-	parameters := make(map[string]string)
-	commonParams, _ := userInputs["common_parameters"].(map[string]interface{})
-	apiSpecificParams, _ := userInputs["api_specific_parameters"].(map[string]interface{})
+	parameters := make([]types.ParameterValue, 0, len(apiInfo.Parameters))
+	commonParams := userInputs.CommonParameters
+	apiSpecificParams := userInputs.APISpecificParameters
 
 	fmt.Printf("🔍 DEBUG: Common parameters keys: %v\n", getMapKeys(commonParams))
 	fmt.Printf("🔍 DEBUG: API-specific parameters keys: %v\n", getMapKeys(apiSpecificParams))
@@ -73,72 +139,65 @@ func PrepareAPIExecution(apiList types.APIList, config map[string]interface{}, n
 
 		// Get parameter value from configuration
 		paramValue := getParameterValue(p.Name, commonParams, apiSpecificParams)
-		fmt.Printf("🔍 DEBUG: Parameter %s value: '%s'\n", p.Name, paramValue)
+		fmt.Printf("🔍 DEBUG: Parameter %s value: '%v'\n", p.Name, paramValue)
 
 		// Required parameter validation
-		if p.Required && paramValue == "" {
+		if p.Required && IsEmptyValue(paramValue) {
 			fmt.Printf("❌ Required parameter '%s' is empty or missing\n", p.Name)
 			fmt.Printf("📋 Please fill the 'value' field for '%s' in configuration.yaml\n", p.Name)
 			fmt.Printf("🛑 Execution stopped - configuration incomplete\n")
 			return nil, fmt.Errorf("required parameter '%s' is empty or missing (check configuration.yaml)", p.Name)
 		}
 
-		// Add parameter to map (even if empty for optional parameters)
-		// Empty optional parameters will be filtered out in buildFinalURL
-		parameters[p.Name] = paramValue
+		// Add parameter with its serialization rules (even if empty for optional parameters)
+		// Empty optional parameters will be filtered out in SerializeParameters
+		parameters = append(parameters, types.ParameterValue{
+			Name:        p.Name,
+			In:          p.In,
+			Style:       p.Style,
+			Explode:     p.Explode,
+			ContentType: p.ContentType,
+			Value:       paramValue,
+		})
 	}
 
-	fmt.Printf("🔍 DEBUG: Final parameters map: %v\n", parameters)
-
-	// Prepare request body - only required fields
-	var requestBody interface{}
-	if len(apiInfo.RequestBodySchema.RequiredFields) > 0 {
-		fmt.Printf("🔍 DEBUG: Processing request body with %d required fields: %v\n",
-			len(apiInfo.RequestBodySchema.RequiredFields), apiInfo.RequestBodySchema.RequiredFields)
-
-		commonBodies, _ := userInputs["common_request_bodies"].(map[string]interface{})
-		apiSpecificBodies, _ := userInputs["api_specific_request_bodies"].(map[string]interface{})
-
-		fmt.Printf("🔍 DEBUG: Common bodies keys: %v\n", getMapKeys(commonBodies))
-		fmt.Printf("🔍 DEBUG: API-specific bodies keys: %v\n", getMapKeys(apiSpecificBodies))
+	fmt.Printf("🔍 DEBUG: Final parameters: %v\n", parameters)
 
-		bodyMap := make(map[string]interface{})
-		schemaName := apiInfo.RequestBodySchema.SchemaName
-		fmt.Printf("🔍 DEBUG: Schema name: %s\n", schemaName)
-
-		for _, fieldName := range apiInfo.RequestBodySchema.RequiredFields {
-			fmt.Printf("🔍 DEBUG: Processing required field: %s\n", fieldName)
-
-			fieldValue := getRequestBodyFieldValue(fieldName, schemaName, commonBodies, apiSpecificBodies)
-			fmt.Printf("🔍 DEBUG: Field %s value: %v\n", fieldName, fieldValue)
-			if fieldValue == nil || fieldValue == "" {
-				fmt.Printf("❌ Required request body field '%s' is empty or missing\n", fieldName)
-				fmt.Printf("📋 Please fill the 'value' field for '%s' in configuration.yaml under '%s' schema\n", fieldName, schemaName)
-				fmt.Printf("🛑 Execution stopped - configuration incomplete\n")
-				return nil, fmt.Errorf("required request body field '%s' is empty or missing (check configuration.yaml)", fieldName)
-			}
-
-			bodyMap[fieldName] = fieldValue
-		}
+	// Prepare request body - start from the body generated from the schema, then
+	// apply configured field values and JSON-path overrides
+	apiRef := APIRequestBodyKey(nf, resolved.Name)
+	requestBody, err := buildRequestBody(apiInfo, apiRef, userInputs)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(bodyMap) > 0 {
-			requestBody = bodyMap
-		}
-		fmt.Printf("🔍 DEBUG: Final request body: %v\n", requestBody)
-	} else if apiInfo.RequestBody != "" {
-		fmt.Printf("🔍 DEBUG: Using default request body for type: %s\n", apiInfo.RequestBody)
-		// Fallback to default request body if no schema available
-		requestBody = GetDefaultRequestBodyForType(apiInfo.RequestBody)
+	// Media type the operation declares (or the API entry selects), JSON Patch and multipart shaping
+	contentType, requestBody, parts, err := resolveRequestContent(apiInfo, apiRef, userInputs, requestBody)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, fmt.Errorf("request body of %s: %w", apiRef, err)
 	}
 
 	execInfo := &types.APIExecutionInfo{
 		NF:          nf,
-		APIName:     apiName,
+		APIName:     resolved.Name,
+		Service:     resolved.Service,
 		Method:      apiInfo.Method,
 		Path:        apiInfo.Path,
+		ServicePath: resolved.ServiceInfo.Path,
 		Parameters:  parameters,
 		RequestBody: requestBody,
 		Headers:     make(map[string]string),
+		ScopeSets:   apiInfo.Scopes,
+		ContentType: contentType,
+		Parts:       parts,
+	}
+	if specFile := resolved.ServiceInfo.SpecFile; specFile != "" {
+		// Release bundles live in rel-* subdirectories of the openapi directory
+		execInfo.SpecFile = path.Join(resolved.ServiceInfo.Release, specFile)
+	}
+	if len(execInfo.ScopeSets) == 0 && resolved.ServiceInfo.APIName != "" {
+		execInfo.ScopeSets = [][]string{{resolved.ServiceInfo.APIName}}
 	}
 
 	fmt.Printf("✅ Configuration validation passed - ready for execution\n")
@@ -146,6 +205,123 @@ func PrepareAPIExecution(apiList types.APIList, config map[string]interface{}, n
 	return execInfo, nil
 }
 
+// buildRequestBody builds the request body of an API from its generated example and configuration.yaml
+func buildRequestBody(apiInfo *types.APIListEntry, apiRef string, userInputs types.UserInputSection) (interface{}, error) {
+	schemaName := apiInfo.RequestBodySchema.SchemaName
+
+	if apiInfo.RequestBodyExample != nil || len(apiInfo.RequestBodySchema.RequiredFields) > 0 {
+		fmt.Printf("🔍 DEBUG: Schema name: %s\n", schemaName)
+		fmt.Printf("🔍 DEBUG: Common bodies keys: %v\n", getMapKeys(userInputs.CommonRequestBodies))
+		fmt.Printf("🔍 DEBUG: API-specific bodies keys: %v\n", getMapKeys(userInputs.APISpecificRequestBodies))
+	}
+
+	requestBody, missing, err := composeRequestBody(apiInfo, apiRef, userInputs)
+	if len(missing) > 0 {
+		fieldName := missing[0]
+		fmt.Printf("❌ Required request body field '%s' is empty or missing\n", fieldName)
+		fmt.Printf("📋 Please fill the 'value' field for '%s' in configuration.yaml under '%s' schema\n", fieldName, schemaName)
+		fmt.Printf("🛑 Execution stopped - configuration incomplete\n")
+		return nil, fmt.Errorf("required request body field '%s' is empty or missing (check configuration.yaml)", fieldName)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, err
+	}
+
+	if requestBody != nil {
+		fmt.Printf("🔍 DEBUG: Final request body: %v\n", requestBody)
+	}
+	return requestBody, nil
+}
+
+// composeRequestBody merges the generated example (or the configured body file), configured
+// field values and JSON-path overrides. The entry of the API itself (NF/API name) is applied
+// last. Required fields that end up missing are returned instead of an error.
+func composeRequestBody(apiInfo *types.APIListEntry, apiRef string, userInputs types.UserInputSection) (interface{}, []string, error) {
+	schemaName := apiInfo.RequestBodySchema.SchemaName
+	commonBodies := userInputs.CommonRequestBodies
+	apiSpecificBodies := userInputs.APISpecificRequestBodies
+
+	apiKey, apiBody := lookupAPIRequestBody(apiSpecificBodies, apiRef, apiInfo.Key)
+	bodyFile := requestBodyFile(apiBody, schemaName, commonBodies, apiSpecificBodies)
+
+	if apiInfo.RequestBodyExample == nil && len(apiInfo.RequestBodySchema.RequiredFields) == 0 && bodyFile == "" && apiBody == nil {
+		if apiInfo.RequestBody == "" {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("api_list.yaml has no %s body example for %s, rebuild it with -b or set a body 'file' for the API",
+			apiInfo.RequestBody, apiRef)
+	}
+
+	// Deep copy, the example is shared by every execution of the API
+	requestBody := normalizeJSONValue(apiInfo.RequestBodyExample)
+	if bodyFile != "" {
+		fmt.Printf("🔍 DEBUG: Request body file: %s\n", bodyFile)
+		var err error
+		if requestBody, err = loadRequestBodyFile(bodyFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	bodyMap, isObject := requestBody.(map[string]interface{})
+	if requestBody == nil {
+		bodyMap, isObject = make(map[string]interface{}), true
+	}
+
+	// Configured top-level field values replace the generated ones
+	var missing []string
+	if isObject {
+		for _, fieldName := range configuredBodyFields(schemaName, commonBodies, apiSpecificBodies) {
+			if fieldValue := getBodyFieldValue(fieldName, schemaName, commonBodies, apiSpecificBodies); !IsEmptyValue(fieldValue) {
+				bodyMap[fieldName] = fieldValue
+			}
+		}
+		for _, fieldName := range configuredBodyFields(apiKey, nil, apiSpecificBodies) {
+			if fieldValue, _ := lookupBodyField(apiSpecificBodies, apiKey, fieldName); !IsEmptyValue(fieldValue) {
+				bodyMap[fieldName] = normalizeJSONValue(fieldValue)
+			}
+		}
+
+		for _, fieldName := range apiInfo.RequestBodySchema.RequiredFields {
+			if _, exists := bodyMap[fieldName]; !exists {
+				missing = append(missing, fieldName)
+			}
+		}
+		requestBody = bodyMap
+	}
+
+	// JSON-path overrides: common, then API-specific ones of the schema, then those of the API
+	for _, entry := range []struct {
+		bodies map[string]interface{}
+		name   string
+	}{{commonBodies, schemaName}, {apiSpecificBodies, schemaName}, {apiSpecificBodies, apiKey}} {
+		body, _ := entry.bodies[entry.name].(map[string]interface{})
+		overrides, _ := normalizeJSONValue(body["overrides"]).(map[string]interface{})
+		if len(overrides) == 0 {
+			continue
+		}
+
+		var err error
+		if requestBody, err = ApplyJSONPathOverrides(requestBody, overrides); err != nil {
+			return requestBody, missing, fmt.Errorf("invalid request body override for '%s': %w", entry.name, err)
+		}
+	}
+
+	return requestBody, missing, nil
+}
+
+// configuredBodyFields returns the property names configured for a request body schema
+func configuredBodyFields(schemaName string, commonBodies, apiSpecificBodies map[string]interface{}) []string {
+	seen := make(map[string]interface{})
+	for _, bodies := range []map[string]interface{}{commonBodies, apiSpecificBodies} {
+		body, _ := bodies[schemaName].(map[string]interface{})
+		properties, _ := body["properties"].(map[string]interface{})
+		for fieldName := range properties {
+			seen[fieldName] = true
+		}
+	}
+	return sortedMapKeys(seen)
+}
+
 // getMapKeys returns keys of a map for debugging
 func getMapKeys(m map[string]interface{}) []string {
 	if m == nil {
@@ -158,7 +334,8 @@ func getMapKeys(m map[string]interface{}) []string {
 	return keys
 }
 
-func getParameterValue(paramName string, commonParams, apiSpecificParams map[string]interface{}) string {
+// getParameterValue returns the configured value, which may be a scalar, list or object
+func getParameterValue(paramName string, commonParams, apiSpecificParams map[string]interface{}) interface{} {
 	// Check common parameters first
 	val, found := lookupParam(commonParams, paramName)
 	if found {
@@ -171,48 +348,36 @@ func getParameterValue(paramName string, commonParams, apiSpecificParams map[str
 		return val
 	}
 
-	return ""
+	return nil
 }
 
 // lookupParam looks up parameter value from configuration node
-func lookupParam(params map[string]interface{}, key string) (string, bool) {
+func lookupParam(params map[string]interface{}, key string) (interface{}, bool) {
 	node, ok := params[key]
 	if !ok {
-		return "", false
+		return nil, false
 	}
 
-	// New YAML format: paramName: {value: "..."}
+	// New YAML format: paramName: {value: ...}
 	if nodeMap, ok := node.(map[string]interface{}); ok {
-		if val, ok := nodeMap["value"].(string); ok {
-			return val, true
+		if val, exists := nodeMap["value"]; exists {
+			return normalizeJSONValue(val), true
 		}
+		return nil, false
 	}
 
-	// Old format or direct string value
-	if str, ok := node.(string); ok {
-		return str, true
-	}
-
-	return "", false
+	// Old format or direct value
+	return normalizeJSONValue(node), true
 }
 
-// getRequestBodyFieldValue gets request body field value from configuration with improved lookup
-func getRequestBodyFieldValue(fieldName, schemaName string, commonBodies, apiSpecificBodies map[string]interface{}) interface{} {
-	fmt.Printf("🔍 DEBUG: Looking up body field: %s in schema: %s\n", fieldName, schemaName)
-
-	// Check common request bodies first
+// getBodyFieldValue returns the configured value of a request body field, common bodies first
+func getBodyFieldValue(fieldName, schemaName string, commonBodies, apiSpecificBodies map[string]interface{}) interface{} {
 	if val, ok := lookupBodyField(commonBodies, schemaName, fieldName); ok {
-		fmt.Printf("🔍 DEBUG: Found in common bodies: %s = %v\n", fieldName, val)
-		return val
+		return normalizeJSONValue(val)
 	}
-
-	// Check API-specific request bodies
 	if val, ok := lookupBodyField(apiSpecificBodies, schemaName, fieldName); ok {
-		fmt.Printf("🔍 DEBUG: Found in API-specific bodies: %s = %v\n", fieldName, val)
-		return val
+		return normalizeJSONValue(val)
 	}
-
-	fmt.Printf("🔍 DEBUG: Body field %s not found in configuration\n", fieldName)
 	return nil
 }
 
@@ -271,56 +436,3 @@ func GenerateDefaultParameterValue(paramName string) string {
 		return "example-value"
 	}
 }
-
-// GetDefaultRequestBodyForType returns a default request body for a given type
-func GetDefaultRequestBodyForType(bodyType string) interface{} {
-	defaults := map[string]interface{}{
-		"AuthenticationInfo": map[string]interface{}{
-			"servingNetworkName": "5G:mnc093.mcc208.3gppnetwork.org",
-			"resynchronizationInfo": map[string]interface{}{
-				"rand": "00112233445566778899aabbccddeeff",
-				"auts": "fedcba9876543210",
-			},
-			"supportedFeatures": "example-features",
-		},
-		"subscription_data": map[string]interface{}{
-			"callbackReference": "http://callback.example.com/notifications",
-			"monitoringConfigurations": map[string]interface{}{
-				"eventType":     "LOCATION_REPORTING",
-				"immediateFlag": true,
-			},
-			"supportedFeatures": "example-features",
-		},
-		"pdu_session_info": map[string]interface{}{
-			"pduSessionType": "IPV4",
-			"sscMode":        "SSC_MODE_1",
-			"dnn":            "internet",
-			"snssai": map[string]interface{}{
-				"sst": 1,
-				"sd":  "123456",
-			},
-		},
-		"nf_profile": map[string]interface{}{
-			"nfInstanceId":  "example-nf-instance-id",
-			"nfType":        "AUSF",
-			"nfStatus":      "REGISTERED",
-			"ipv4Addresses": []string{"192.168.1.100"},
-		},
-		"patch_request": []map[string]interface{}{
-			{
-				"op":    "replace",
-				"path":  "/nfStatus",
-				"value": "REGISTERED",
-			},
-		},
-	}
-
-	if body, exists := defaults[bodyType]; exists {
-		return body
-	}
-
-	return map[string]interface{}{
-		"data":              "example-data",
-		"supportedFeatures": "example-features",
-	}
-}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIRequestBodyKey returns the api_specific_request_bodies key of one API (NF/API name)
+func APIRequestBodyKey(nf, apiName string) string {
+	return nf + "/" + apiName
+}
+
+// isAPIRequestBodyKey checks if an entry is keyed per API rather than per schema.
+// Such entries are added by the user and never generated.
+func isAPIRequestBodyKey(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// lookupAPIRequestBody finds the entry of an API by NF/API name or by its api_list key
+func lookupAPIRequestBody(bodies map[string]interface{}, apiRef, apiKey string) (string, map[string]interface{}) {
+	for _, key := range []string{apiRef, apiKey} {
+		if body, ok := bodies[key].(map[string]interface{}); ok && key != "" {
+			return key, body
+		}
+	}
+	return "", nil
+}
+
+// requestBodyFile returns the body file configured for an API: its own entry first,
+// then the API-specific and common entries of its schema
+func requestBodyFile(apiBody map[string]interface{}, schemaName string, commonBodies, apiSpecificBodies map[string]interface{}) string {
+	if file := cfgText(apiBody["file"]); file != "" {
+		return file
+	}
+	for _, bodies := range []map[string]interface{}{apiSpecificBodies, commonBodies} {
+		body, _ := bodies[schemaName].(map[string]interface{})
+		if file := cfgText(body["file"]); file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+// loadRequestBodyFile reads a JSON or YAML request body. Relative paths are relative
+// to the configuration file.
+func loadRequestBodyFile(file string) (interface{}, error) {
+	path := resolveConfigPath(file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body file: %w", err)
+	}
+
+	// JSON is read as YAML, which keeps large integers such as SUPI digits exact
+	var body interface{}
+	if err := yaml.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("request body file %s: %w", path, err)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("request body file %s is empty", path)
+	}
+	return normalizeJSONValue(body), nil
+}
+
+// resolveConfigPath resolves a path given in the configuration relative to the configuration file
+func resolveConfigPath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(ConfigurationPath()), path)
+}
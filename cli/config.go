@@ -12,8 +12,9 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// BuildConfiguration creates configuration.yaml and api_list.yaml
-func BuildConfiguration(services map[string]types.ServiceMetadata, nfFilter string) error {
+// BuildConfiguration creates configuration.yaml and api_list.yaml. Values entered in an
+// existing configuration.yaml are kept unless fresh is set, the previous file is backed up.
+func BuildConfiguration(services map[string]types.ServiceMetadata, nfFilter string, fresh bool) error {
 	nfServices := groupServicesByNF(services, nfFilter)
 
 	if len(nfServices) == 0 {
@@ -25,10 +26,19 @@ func BuildConfiguration(services map[string]types.ServiceMetadata, nfFilter stri
 		UserInputs: buildUserInputSection(nfServices),
 	}
 
+	if err := mergeExistingConfiguration(&config, ConfigurationPath(), nfFilter, fresh); err != nil {
+		return err
+	}
+
 	if err := writeConfigurationFile(config, nfFilter); err != nil {
 		return err
 	}
 
+	// Keep user overrides of the NF mapping across rebuilds
+	if err := writeNFMappingTemplate(); err != nil {
+		return err
+	}
+
 	// Build and write API list
 	apiList := buildAPIList(nfServices)
 	return writeAPIListFile(apiList)
@@ -61,42 +71,80 @@ func buildAPIList(nfServices map[string][]types.ServiceMetadata) types.APIList {
 		for _, service := range serviceList {
 			serviceName := CleanServiceName(service.Name)
 			servicePath := ExtractServicePath(service)
-			serviceVersion := ExtractVersionFromPath(servicePath)
 
 			serviceAPIs := make(map[string]types.APIListEntry)
 
-			for apiName, api := range service.APIs {
+			for _, apiName := range getSortedKeys(service.APIs) {
+				api := service.APIs[apiName]
 				method := ExtractMethodFromAPIName(apiName)
 				cleanName := CleanAPIName(apiName)
 
+				// Same name with another method: keep the method suffix for this entry
+				if _, exists := serviceAPIs[cleanName]; exists {
+					fmt.Printf("⚠️  API name collision in %s: '%s' is used by several methods, registering '%s'\n",
+						serviceName, cleanName, apiName)
+					cleanName = apiName
+				}
+
 				parameterInfos := buildParameterInfos(api, service)
 
 				requestBodyInfo := buildRequestBodyInfo(api)
 
 				serviceAPIs[cleanName] = types.APIListEntry{
-					Path:              api.Path,
-					Method:            method,
-					Parameters:        parameterInfos,
-					RequestBody:       api.RequestBody,
-					RequestBodySchema: requestBodyInfo,
+					Key:                api.Key,
+					Release:            service.Release,
+					SpecVersion:        service.Server.FullVersion,
+					Path:               api.Path,
+					Method:             method,
+					Parameters:         parameterInfos,
+					RequestBody:        api.RequestBody,
+					RequestBodySchema:  requestBodyInfo,
+					RequestBodyExample: api.RequestBodyExample,
+					ContentTypes:       api.RequestContentTypes,
+					RequestParts:       api.RequestParts,
+					Scopes:             ExtractScopeSets(api.Security),
+					Security:           api.Security,
 				}
 			}
 
 			nfAPIList[serviceName] = types.ServiceAPIList{
-				Path:    servicePath,
-				Version: serviceVersion,
-				APIs:    serviceAPIs,
+				Path:        servicePath,
+				Version:     service.Server.Version,
+				FullVersion: service.Server.FullVersion,
+				APIRoot:     service.Server.APIRoot,
+				APIName:     service.Server.APIName,
+				SpecFile:    service.SpecFile,
+				Release:     service.Release,
+				APIs:        serviceAPIs,
 			}
 		}
 
 		if len(nfAPIList) > 0 {
 			apiList[nf] = nfAPIList
+			warnAmbiguousAPIs(nf, nfAPIList)
 		}
 	}
 
 	return apiList
 }
 
+// warnAmbiguousAPIs - Warn about API names that need -s SERVICE or the unique key to be addressed
+func warnAmbiguousAPIs(nf string, nfAPIList map[string]types.ServiceAPIList) {
+	owners := make(map[string][]string)
+	for _, serviceName := range getSortedKeys(nfAPIList) {
+		for apiName := range nfAPIList[serviceName].APIs {
+			owners[apiName] = append(owners[apiName], serviceName)
+		}
+	}
+
+	for _, apiName := range getSortedKeys(owners) {
+		if services := owners[apiName]; len(services) > 1 {
+			fmt.Printf("⚠️  API '%s' is ambiguous in NF %s (services: %s), address it with -s SERVICE or its key\n",
+				apiName, nf, strings.Join(services, ", "))
+		}
+	}
+}
+
 // buildUserInputSection - Build user-friendly structure
 func buildUserInputSection(nfServices map[string][]types.ServiceMetadata) types.UserInputSection {
 	userInputs := types.UserInputSection{
@@ -144,11 +192,37 @@ func buildGlobalSettingsSection() map[string]interface{} {
 			"description": "Number of concurrent requests",
 			"type":        "integer",
 		},
+		"release": map[string]interface{}{
+			"value":       "",
+			"description": "3GPP release bundle under openapi/ (e.g. rel-17), empty selects the unversioned bundle, else the latest",
+		},
 		"use_https": map[string]interface{}{
 			"value":       false,
 			"description": "Whether to use HTTPS",
 			"type":        "boolean",
 		},
+		"discovery_cache": map[string]interface{}{
+			"value":       DiscoveryCacheMemory,
+			"description": "NRF discovery result cache: memory (one run), disk (" + DiscoveryCachePath + ", shared by runs) or off. Results expire after their validityPeriod",
+		},
+		"dataset_file": map[string]interface{}{
+			"value":       "",
+			"description": "Subscriber dataset (.csv with a header row, or .yaml list) read by {{subscriber.supi}}, {{subscriber.gpsi}}, ...",
+		},
+		"dataset_order": map[string]interface{}{
+			"value":       types.DatasetSequential,
+			"description": "Order subscribers are drawn in: " + strings.Join(types.DatasetOrders, ", "),
+		},
+		"dataset_zipf_s": map[string]interface{}{
+			"value":       defaultZipfSkew,
+			"description": "Skew of the zipf order (> 1), higher values concentrate requests on the first subscribers",
+			"type":        "number",
+		},
+		"dataset_seed": map[string]interface{}{
+			"value":       0,
+			"description": "Seed of the random, uniform and zipf orders, 0 draws differently on every run",
+			"type":        "integer",
+		},
 	}
 }
 
@@ -161,9 +235,74 @@ func buildNFSettingsSection(nfServices map[string][]types.ServiceMetadata) map[s
 		nfSettings[nf] = map[string]interface{}{
 			"enabled": map[string]interface{}{
 				"value":       true,
-				"description": fmt.Sprintf("Enable %s NF", nf),
+				"description": fmt.Sprintf("Enable %s NF, runs of a disabled NF are refused", nf),
 				"type":        "boolean",
 			},
+			"release": map[string]interface{}{
+				"value":       "",
+				"description": fmt.Sprintf("3GPP release bundle implemented by %s (overrides global release, generated from %s)", nf, formatRelease(nfServices[nf])),
+			},
+			"http_version": map[string]interface{}{
+				"value":       "",
+				"description": "HTTP version: 1.1, 2 (h2c prior knowledge over http), empty for the Go default",
+				"type":        "string",
+			},
+			"base_url": map[string]interface{}{
+				"value":       "",
+				"description": fmt.Sprintf("Base URL of %s (e.g. http://10.0.0.5:8000), skips NF discovery when set", nf),
+				"type":        "string",
+			},
+			"load_balancing": map[string]interface{}{
+				"value":       "round_robin",
+				"description": "How requests are spread across discovered instances: round_robin, random, weighted (best priority, by capacity) or first",
+				"type":        "string",
+			},
+			"timeout_seconds": map[string]interface{}{
+				"value":       0,
+				"description": "Request timeout in seconds, 0 uses global timeout_seconds",
+				"type":        "integer",
+			},
+			"rate_limit_rps": map[string]interface{}{
+				"value":       0,
+				"description": "Maximum requests per second, 0 for unlimited",
+				"type":        "number",
+			},
+			"tls_insecure_skip_verify": map[string]interface{}{
+				"value":       false,
+				"description": "Skip verification of the server certificate",
+				"type":        "boolean",
+			},
+			"tls_ca_file": map[string]interface{}{
+				"value":       "",
+				"description": "PEM file of the CA certificates verifying the server",
+				"type":        "string",
+			},
+			"tls_cert_file": map[string]interface{}{
+				"value":       "",
+				"description": "PEM client certificate for mutual TLS (with tls_key_file)",
+				"type":        "string",
+			},
+			"tls_key_file": map[string]interface{}{
+				"value":       "",
+				"description": "PEM private key of tls_cert_file",
+				"type":        "string",
+			},
+			"auth_mode": map[string]interface{}{
+				"value":       "none",
+				"description": "Authorization: none, bearer (auth_token) or oauth2 (client credentials token from the NRF)",
+				"type":        "string",
+			},
+			"auth_token": map[string]interface{}{
+				"value":       "",
+				"description": "Static bearer token for auth_mode bearer",
+				"type":        "string",
+			},
+			"auth_scopes": map[string]interface{}{
+				"value":       "operation",
+				"description": "Security alternative oauth2 tokens are requested for: operation (most scopes, e.g. nudm-sdm nudm-sdm:nssai:read) or service (fewest)",
+				"type":        "string",
+			},
+			"discovery_query": buildDiscoveryQuerySection(nf),
 			"custom_headers": map[string]interface{}{
 				"Content-Type": map[string]interface{}{
 					"value":       "application/json",
@@ -180,6 +319,40 @@ func buildNFSettingsSection(nfServices map[string][]types.ServiceMetadata) map[s
 	return nfSettings
 }
 
+// buildDiscoveryQuerySection - Build the Nnrf_NFDiscovery query parameters sent when discovering the NF
+func buildDiscoveryQuerySection(nf string) map[string]interface{} {
+	descriptions := map[string]string{
+		"service-names":         fmt.Sprintf("Services the %s instance must offer, list or comma-separated", nf),
+		"snssais":               "Slices the instance must serve, list of {sst, sd} or SST-SD strings",
+		"dnn":                   "DNN the instance must serve",
+		"target-plmn-list":      "PLMNs of the instance, list of {mcc, mnc} or MCC-MNC strings",
+		"preferred-locality":    "Preferred locality of the instance",
+		"supi":                  "SUPI the instance must serve (e.g. imsi-208930000000001)",
+		"routing-indicator":     "Routing indicator of the subscriber (AUSF, UDM)",
+		"target-nf-instance-id": "NF instance ID of the instance",
+		"requester-features":    "Features of the NF discovery service the requester supports (hex string)",
+	}
+
+	section := make(map[string]interface{}, len(descriptions))
+	for _, name := range discoveryQueryParameters {
+		section[name] = map[string]interface{}{
+			"value":       "",
+			"description": descriptions[name],
+		}
+	}
+	return section
+}
+
+// formatRelease - Describe the release bundle the NF's services were generated from
+func formatRelease(serviceList []types.ServiceMetadata) string {
+	for _, service := range serviceList {
+		if service.Release != "" {
+			return service.Release
+		}
+	}
+	return "the unversioned bundle"
+}
+
 // buildCommonParametersSection - Build common parameters in user-friendly format
 func buildCommonParametersSection(nfServices map[string][]types.ServiceMetadata) map[string]interface{} {
 	extractedParams := extractParametersFromSpecs(nfServices)
@@ -275,21 +448,21 @@ func buildAPISpecificRequestBodiesSection(nfServices map[string][]types.ServiceM
 func buildParameterInfos(api types.APIMetadata, service types.ServiceMetadata) []types.ParamMeta {
 	var paramInfos []types.ParamMeta
 
-	if service.OpenAPISpec != nil {
-		// Extract parameter info from OpenAPI spec
-		for _, pathItem := range service.OpenAPISpec.Paths {
-			if pathItem.Get != nil && matchesAPI(pathItem.Get, api) {
-				paramInfos = extractParameterInfosFromOperation(pathItem.Get)
-			} else if pathItem.Post != nil && matchesAPI(pathItem.Post, api) {
-				paramInfos = extractParameterInfosFromOperation(pathItem.Post)
-			} else if pathItem.Put != nil && matchesAPI(pathItem.Put, api) {
-				paramInfos = extractParameterInfosFromOperation(pathItem.Put)
-			} else if pathItem.Delete != nil && matchesAPI(pathItem.Delete, api) {
-				paramInfos = extractParameterInfosFromOperation(pathItem.Delete)
-			} else if pathItem.Patch != nil && matchesAPI(pathItem.Patch, api) {
-				paramInfos = extractParameterInfosFromOperation(pathItem.Patch)
-			}
+	if operation := FindOperation(service, api); operation != nil {
+		paramInfos = extractParameterInfosFromOperation(operation)
+	}
+
+	// Types and enumerations resolved through $refs by the parser
+	for i, paramInfo := range paramInfos {
+		constraint, exists := api.ParameterConstraints[paramInfo.Name]
+		if !exists || paramInfo.ContentType != "" {
+			continue
 		}
+		if constraint.Type != "" {
+			paramInfos[i].Type = constraint.Type
+		}
+		paramInfos[i].Enum = constraint.Enum
+		paramInfos[i].Extensible = constraint.Extensible
 	}
 
 	// If not found in OpenAPI, create default parameter info
@@ -315,6 +488,7 @@ func buildRequestBodyInfo(api types.APIMetadata) types.BodyMeta {
 
 	requestBodyInfo := types.BodyMeta{
 		SchemaName: api.RequestBody,
+		Fields:     api.RequestBodyFields,
 	}
 
 	// RequestBodySchema에서 required 필드 추출
@@ -345,22 +519,50 @@ func extractParameterInfosFromOperation(operation *types.Operation) []types.Para
 	var paramInfos []types.ParamMeta
 
 	for _, param := range operation.Parameters {
-		paramInfos = append(paramInfos, types.ParamMeta{
+		paramInfo := types.ParamMeta{
 			Name:     param.Name,
 			Required: param.Required,
 			Type:     getSchemaType(param.Schema),
 			In:       param.In,
-		})
+			Style:    param.Style,
+			Explode:  param.Explode.Ptr(),
+		}
+
+		// Parameters with content carry a single media type whose schema describes the value
+		for _, contentType := range getSortedKeys(param.Content) {
+			paramInfo.ContentType = contentType
+			paramInfo.Type = getContentParameterType(param.Content[contentType].Schema)
+			break
+		}
+
+		paramInfos = append(paramInfos, paramInfo)
 	}
 
 	return paramInfos
 }
 
-// matchesAPI - Operation이 API와 일치하는지 확인
-func matchesAPI(operation *types.Operation, api types.APIMetadata) bool {
-	// API 이름에서 메서드 부분 제거하고 비교
-	cleanAPIName := CleanAPIName(api.Name)
-	return operation.OperationID == cleanAPIName
+// getParameterType - Type of a parameter's value, of the media type schema for content parameters
+func getParameterType(param types.Parameter) string {
+	for _, contentType := range getSortedKeys(param.Content) {
+		return getContentParameterType(param.Content[contentType].Schema)
+	}
+	return getSchemaType(param.Schema)
+}
+
+// parameterSchema - Schema of a parameter's value, of its first media type for content parameters
+func parameterSchema(param types.Parameter) types.Schema {
+	for _, contentType := range getSortedKeys(param.Content) {
+		return param.Content[contentType].Schema
+	}
+	return param.Schema
+}
+
+// getContentParameterType - Content parameters usually reference structured types (e.g. PlmnId)
+func getContentParameterType(schema types.Schema) string {
+	if schema.Type == "" && schema.Ref != "" {
+		return "object"
+	}
+	return getSchemaType(schema)
 }
 
 // inferParameterLocation - Infer parameter location (path or query)
@@ -433,6 +635,7 @@ func formatRequestBodyForUser(bodyInfo interface{}) map[string]interface{} {
 			"description":     bodyMap["description"],
 			"type":            bodyMap["type"],
 			"required_fields": bodyMap["required"],
+			"overrides":       map[string]interface{}{}, // JSON path → value, applied to the generated body
 		}
 
 		if properties, exists := bodyMap["properties"].(map[string]interface{}); exists {
@@ -475,8 +678,9 @@ func formatRequestBodyForUser(bodyInfo interface{}) map[string]interface{} {
 func extractParametersFromSpecs(nfServices map[string][]types.ServiceMetadata) map[string]interface{} {
 	params := make(map[string]interface{})
 
-	for _, serviceList := range nfServices {
-		for _, service := range serviceList {
+	// NFs in sorted order, so rebuilds pick the same definition of a shared name
+	for _, nf := range getSortedNFNames(nfServices) {
+		for _, service := range nfServices[nf] {
 			if service.OpenAPISpec != nil {
 				extractParametersFromOpenAPISpec(service.OpenAPISpec, params)
 			}
@@ -501,9 +705,9 @@ func extractParametersFromOpenAPISpec(spec *types.OpenAPISpec, params map[string
 						params[param.Name] = map[string]interface{}{
 							"description": getParameterDescription(param),
 							"required":    param.Required,
-							"type":        getSchemaType(param.Schema),
+							"type":        getParameterType(param),
 							"in":          param.In,
-							"example":     generateExampleFromSchema(param.Schema),
+							"example":     generateExampleFromSchema(parameterSchema(param)),
 							"value":       "", // User input field
 						}
 					}
@@ -534,6 +738,11 @@ func getSchemaType(schema types.Schema) string {
 
 // generateExampleFromSchema - Generate example value from schema
 func generateExampleFromSchema(schema types.Schema) interface{} {
+	// Prefer const/example/examples declared by the specification
+	if example, ok := schema.DeclaredExample(); ok {
+		return example
+	}
+
 	switch schema.Type {
 	case "string":
 		if schema.Format == "uuid" {
@@ -557,8 +766,9 @@ func generateExampleFromSchema(schema types.Schema) interface{} {
 func extractRequestBodiesFromSpecs(nfServices map[string][]types.ServiceMetadata) map[string]interface{} {
 	bodies := make(map[string]interface{})
 
-	for _, serviceList := range nfServices {
-		for _, service := range serviceList {
+	// NFs in sorted order, so rebuilds pick the same definition of a shared name
+	for _, nf := range getSortedNFNames(nfServices) {
+		for _, service := range nfServices[nf] {
 			if service.OpenAPISpec != nil {
 				extractRequestBodiesFromOpenAPISpec(service.OpenAPISpec, bodies)
 			}
@@ -581,7 +791,7 @@ func extractRequestBodiesFromOpenAPISpec(spec *types.OpenAPISpec, bodies map[str
 					if strings.Contains(contentType, "json") {
 						schemaName := extractSchemaNameFromMediaType(mediaType)
 						if schemaName != "" && spec.Components != nil {
-							if schemaDef, exists := spec.Components.Schemas[schemaName]; exists {
+							if schemaDef, exists := spec.Components.LookupSchema(schemaName); exists {
 								bodies[schemaName] = convertSchemaDefinitionToTemplate(schemaName, schemaDef)
 							}
 						}
@@ -692,6 +902,11 @@ func convertPropertyToTemplate(propName string, propDef interface{}, requiredFie
 		template["example"] = generateExampleByFormat(format.(string))
 	}
 
+	// Handle example declared by the specification (3.1 examples are folded into example)
+	if example, exists := propMap["example"]; exists {
+		template["example"] = example
+	}
+
 	return template
 }
 
@@ -869,14 +1084,21 @@ func writeAPIListFile(apiList types.APIList) error {
 # Structure:
 #   NF_NAME:
 #     SERVICE_NAME:
-#       path: /service-base-path/version
+#       path: /service-base-path/version (empty when served at {apiRoot})
 #       version: v1
+#       full_version: info.version of the specification
+#       api_root: server URL root variable (apiRoot, nrfApiRoot)
+#       api_name: 3GPP API name (e.g. nudm-sdm)
+#       release: 3GPP release bundle the specification came from (e.g. rel-17)
 #       apis:
 #         API_NAME:
 #           path: /api-specific-path
 #           method: HTTP_METHOD
-#           parameters: [list of parameters]
+#           parameters: [name, required, type, in, style, explode, content_type]
 #           request_body: request_body_schema_name
+#           request_body_example: body synthesized from the resolved schema (sent by default)
+#           scopes: [OAuth2 scopes the access token must carry]
+#           release / spec_version: release bundle and info.version of the API
 # =============================================================================
 
 `
@@ -896,8 +1118,28 @@ func writeAPIListFile(apiList types.APIList) error {
 	return nil
 }
 
+// profilesExample is written while the configuration defines no profiles
+const profilesExample = `# profiles:
+#   lab-free5gc:
+#     global_settings:
+#       nrf_url:
+#         value: http://10.96.217.220:8000
+#       requester_nf_type:
+#         value: AMF
+#     nf_settings:
+#       AMF:
+#         enabled:
+#           value: true
+#   staging:
+#     global_settings:
+#       nrf_url:
+#         value: https://nrf.staging.example.com
+#       use_https:
+#         value: true
+`
+
 func writeConfigurationFile(config types.ConfigurationFile, nfFilter string) error {
-	filename := "configuration.yaml"
+	filename := ConfigurationPath()
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create configuration file: %w", err)
@@ -919,7 +1161,34 @@ func writeConfigurationFile(config types.ConfigurationFile, nfFilter string) err
 # Important Notes:
 # - Only enter actual values in the 'value' fields
 # - Fields with required=true must have values
+# - Request bodies are generated from the schema with all required fields, 'value' fields replace top-level fields
+# - 'overrides' set nested fields by JSON path (e.g. plmnId.mcc: "001", /nfServices/0/versions: [...])
+# - 'file: bodies/policy.json' sends a complete JSON/YAML body (relative to this file) instead of the
+#   generated one, field values and overrides still apply on top
+# - api_specific_request_bodies may hold entries for one API, keyed NF/API name, kept by -b:
+#     PCF/CreateSMPolicy: {file: bodies/sm-policy.json, overrides: {supi: "{{subscriber.supi}}"}}
+# - Bodies are sent with the operation's first declared content type; an API entry may pick another
+#   with 'content_type'. JSON Patch bodies are a list of operations or path → value replacements,
+#   multipart/related bodies take binary 'parts' from a file or hex:
+#     SMF/PostSmContexts: {parts: {binaryDataN1SmMessage: {hex: "2e0101c1ffff91", content_id: n1msg}}}
+#   Every contentId of the body must be the content_id of a configured part (by default its name)
 # - 'example' fields are for reference only, do not modify them
+# - Array/object parameters accept YAML lists/maps and are serialized per style/explode
+# - Rebuilding with -b keeps entered values, entries no longer generated are marked 'obsolete: true'
+# - Profiles at the end of the file switch environments with -profile NAME
+# - Values, headers and overrides may hold templates evaluated per request: {{uuid}}, {{seq}},
+#   {{worker}}, {{now.rfc3339}}, {{randInt 1 255}}, {{supi from 208930000000001 step 1}}
+#   A value that is only {{seq}}, {{worker}}, {{randInt ...}} or {{now.unix}} is sent as a number
+# - NRF discovery results are cached for their validityPeriod (global_settings.discovery_cache:
+#   memory, disk or off), run with -refresh-discovery to query the NRF again
+# - Requests are spread across every registered instance the NRF returns (nf_settings.<NF>.load_balancing),
+#   results are also reported per NF instance ID
+# - nf_settings.<NF>.discovery_query narrows NRF discovery with TS 29.510 query parameters:
+#     snssais: ["1-010203"]  or  [{sst: 1, sd: "010203"}],  target-plmn-list: ["208-93"],
+#     service-names: [nausf-auth],  dnn, preferred-locality, supi, routing-indicator, ...
+# - With global_settings.dataset_file, {{subscriber.supi}}, {{subscriber.suci}}, {{subscriber.gpsi}},
+#   {{subscriber.k}}, {{subscriber.opc}}, {{subscriber.snssai}} (sst, sd) and {{subscriber.dnn}}
+#   take one subscriber per request, drawn in dataset_order
 # =============================================================================
 
 user_inputs:
@@ -931,42 +1200,54 @@ user_inputs:
 
 	// Write global_settings section
 	file.WriteString("  global_settings:\n")
-	writeYAMLSection(file, config.UserInputs.GlobalSettings, 2)
+	writeYAMLSection(file, config.UserInputs.GlobalSettings, 4)
 
 	// Write NF settings separator and section
 	file.WriteString("\n# =============================================================================\n")
-	file.WriteString("# NF SETTINGS - Add NF Specific Headers & Is NF Enable\n")
+	file.WriteString("# NF SETTINGS - Per-NF execution policy: enabled, HTTP version, base URL, timeout, rate cap, TLS, auth, headers\n")
 	file.WriteString("# =============================================================================\n")
 	file.WriteString("  nf_settings:\n")
-	writeYAMLSection(file, config.UserInputs.NFSettings, 2)
+	writeYAMLSection(file, config.UserInputs.NFSettings, 4)
 
 	// Write common parameters separator and section
 	file.WriteString("\n# =============================================================================\n")
 	file.WriteString("# COMMON PARAMETERS - Parameters used across multiple APIs\n")
 	file.WriteString("# =============================================================================\n")
 	file.WriteString("  common_parameters:\n")
-	writeYAMLSection(file, config.UserInputs.CommonParameters, 2)
+	writeYAMLSection(file, config.UserInputs.CommonParameters, 4)
 
 	// Write common request bodies separator and section
 	file.WriteString("\n# =============================================================================\n")
 	file.WriteString("# COMMON REQUEST BODIES - Request bodies used across multiple APIs\n")
 	file.WriteString("# =============================================================================\n")
 	file.WriteString("  common_request_bodies:\n")
-	writeYAMLSection(file, config.UserInputs.CommonRequestBodies, 2)
+	writeYAMLSection(file, config.UserInputs.CommonRequestBodies, 4)
 
 	// Write API-specific parameters separator and section
 	file.WriteString("\n# =============================================================================\n")
 	file.WriteString("# API-SPECIFIC PARAMETERS - Parameters specific to each API\n")
 	file.WriteString("# =============================================================================\n")
 	file.WriteString("  api_specific_parameters:\n")
-	writeYAMLSection(file, config.UserInputs.APISpecificParameters, 2)
+	writeYAMLSection(file, config.UserInputs.APISpecificParameters, 4)
 
 	// Write API-specific request bodies separator and section
 	file.WriteString("\n# =============================================================================\n")
 	file.WriteString("# API-SPECIFIC REQUEST BODIES - Request bodies specific to each API\n")
 	file.WriteString("# =============================================================================\n")
 	file.WriteString("  api_specific_request_bodies:\n")
-	writeYAMLSection(file, config.UserInputs.APISpecificRequestBodies, 2)
+	writeYAMLSection(file, config.UserInputs.APISpecificRequestBodies, 4)
+
+	// Write profiles separator and section
+	file.WriteString("\n# =============================================================================\n")
+	file.WriteString("# PROFILES - Named environments selected with -profile (or CTRLBENCH_PROFILE)\n")
+	file.WriteString("# A profile overrides global_settings and nf_settings, parameters and bodies are shared\n")
+	file.WriteString("# =============================================================================\n")
+	if len(config.Profiles) > 0 {
+		file.WriteString("profiles:\n")
+		writeYAMLSection(file, config.Profiles, 2)
+	} else {
+		file.WriteString(profilesExample)
+	}
 
 	fmt.Printf("✅ Configuration file created: %s\n", filename)
 	if nfFilter != "" {
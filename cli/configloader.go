@@ -0,0 +1,496 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigurationFileName is the configuration written by -b and read by API runs
+const ConfigurationFileName = "configuration.yaml"
+
+// configEntryKeys are the fields of a setting, parameter or body property entry
+var configEntryKeys = []string{"value", "description", "type", "required", "example", "obsolete"}
+
+// nfSettingBlocks are the nf_settings keys holding a block of entries rather than one entry
+var nfSettingBlocks = []string{"custom_headers", "discovery_query"}
+
+// requestBodyEntryKeys are the fields of a request body entry
+var requestBodyEntryKeys = []string{"description", "type", "required_fields", "file", "content_type", "parts", "overrides", "properties", "obsolete"}
+
+// LoadConfiguration reads and validates a configuration file with overrides layered on top
+func LoadConfiguration(filename string, overrides ...types.ConfigOverride) (*types.ConfigurationFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return ParseConfiguration(filename, data, overrides...)
+}
+
+// ParseConfiguration validates configuration data against the configuration types and
+// decodes it. Unknown keys and type mismatches are reported with file:line:column
+// (as types.ConfigErrors) instead of being ignored. Overrides are applied in order
+// before validation, problems in their values name the override instead of a line.
+func ParseConfiguration(filename string, data []byte, overrides ...types.ConfigOverride) (*types.ConfigurationFile, error) {
+	root, _, err := parseConfigurationDocument(filename, data, "", overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	var config types.ConfigurationFile
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &config, nil
+}
+
+// LoadConfigurationValues lists the values set in a configuration file, with a profile
+// and overrides layered on top, and where each of them came from
+func LoadConfigurationValues(filename, profile string, overrides ...types.ConfigOverride) ([]types.ConfigValue, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	root, v, err := parseConfigurationDocument(filename, data, profile, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []types.ConfigValue
+	v.collectValues(root, "", &values)
+	return values, nil
+}
+
+// parseConfigurationDocument parses configuration data, applies the profile (if any) and
+// the overrides and validates the result
+func parseConfigurationDocument(filename string, data []byte, profile string, overrides []types.ConfigOverride) (*yaml.Node, *configValidator, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	v := &configValidator{file: filename, origins: make(map[*yaml.Node]string), profiles: make(map[*yaml.Node]string)}
+	if len(doc.Content) == 0 {
+		v.addf(&yaml.Node{Line: 1, Column: 1}, "", "file is empty, rebuild it with -b")
+		return nil, nil, v.errs
+	}
+
+	root := resolveAlias(doc.Content[0])
+	if profile != "" {
+		v.applyProfile(root, profile)
+	}
+	v.applyOverrides(root, overrides)
+	v.validateRoot(root)
+	if len(v.errs) > 0 {
+		sort.SliceStable(v.errs, func(i, j int) bool {
+			if v.errs[i].Line != v.errs[j].Line {
+				return v.errs[i].Line < v.errs[j].Line
+			}
+			return v.errs[i].Column < v.errs[j].Column
+		})
+		return nil, nil, v.errs.Unique()
+	}
+	return root, v, nil
+}
+
+// configValidator collects the problems of one configuration file
+type configValidator struct {
+	file     string
+	errs     types.ConfigErrors
+	origins  map[*yaml.Node]string // Nodes set by overrides and their source
+	profiles map[*yaml.Node]string // Nodes layered from a profile and its name
+}
+
+// addf records a problem at node
+func (v *configValidator) addf(node *yaml.Node, path, format string, args ...interface{}) {
+	if path == "" {
+		path = "(root)"
+	}
+	file := v.file
+	if source, ok := v.origins[node]; ok {
+		file = source
+	}
+	v.errs = append(v.errs, types.ConfigError{
+		File:    file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateRoot checks the top level and the user_inputs sections
+func (v *configValidator) validateRoot(root *yaml.Node) {
+	root = resolveAlias(root)
+	if !v.expectMapping(root, "") {
+		return
+	}
+
+	fields := v.checkKeys(root, "", yamlFieldNames(reflect.TypeOf(types.ConfigurationFile{})))
+	if profiles := fields["profiles"]; profiles != nil && profiles.Tag != "!!null" {
+		v.validateProfiles(profiles)
+	}
+
+	userInputs := fields["user_inputs"]
+	if userInputs == nil {
+		v.addf(root, "", "missing 'user_inputs' section")
+		return
+	}
+	if !v.expectMapping(userInputs, "user_inputs") {
+		return
+	}
+
+	sections := v.checkKeys(userInputs, "user_inputs", yamlFieldNames(reflect.TypeOf(types.UserInputSection{})))
+	for name, section := range sections {
+		path := "user_inputs." + name
+		if section.Kind == yaml.ScalarNode && section.Tag == "!!null" {
+			continue // Empty section
+		}
+		if !v.expectMapping(section, path) {
+			continue
+		}
+
+		switch name {
+		case "global_settings":
+			v.validateGlobalSettings(section, path)
+		case "nf_settings":
+			v.validateNFSettings(section, path)
+		case "common_parameters", "api_specific_parameters":
+			v.validateEntries(section, path)
+		case "common_request_bodies", "api_specific_request_bodies":
+			v.validateRequestBodies(section, path)
+		}
+	}
+}
+
+// validateProfiles checks every profile, selected or not
+func (v *configValidator) validateProfiles(profiles *yaml.Node) {
+	if !v.expectMapping(profiles, "profiles") {
+		return
+	}
+
+	known := yamlFieldNames(reflect.TypeOf(types.ConfigProfile{}))
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		path := "profiles." + profiles.Content[i].Value
+		profile := resolveAlias(profiles.Content[i+1])
+		if profile.Tag == "!!null" || !v.expectMapping(profile, path) {
+			continue
+		}
+
+		for name, section := range v.checkKeys(profile, path, known) {
+			if section.Tag == "!!null" || !v.expectMapping(section, path+"."+name) {
+				continue
+			}
+			switch name {
+			case "global_settings":
+				v.validateGlobalSettings(section, path+"."+name)
+			case "nf_settings":
+				v.validateNFSettings(section, path+"."+name)
+			}
+		}
+	}
+}
+
+// validateGlobalSettings checks settings against the generated global settings
+func (v *configValidator) validateGlobalSettings(section *yaml.Node, path string) {
+	defaults := buildGlobalSettingsSection()
+
+	for name, entry := range v.checkKeys(section, path, getSortedKeys(defaults)) {
+		declared, _ := defaults[name].(map[string]interface{})
+		declaredType, _ := declared["type"].(string)
+		v.validateEntry(entry, path+"."+name, declaredType)
+	}
+}
+
+// validateNFSettings checks each NF block against the generated NF settings
+func (v *configValidator) validateNFSettings(section *yaml.Node, path string) {
+	template := buildNFSettingsSection(map[string][]types.ServiceMetadata{"NF": nil})["NF"]
+	known := getSortedKeys(template)
+
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		nf, settings := section.Content[i].Value, resolveAlias(section.Content[i+1])
+		nfPath := path + "." + nf
+		if settings.Tag == "!!null" || !v.expectMapping(settings, nfPath) {
+			continue
+		}
+
+		for name, entry := range v.checkKeys(settings, nfPath, known) {
+			if !containsString(nfSettingBlocks, name) {
+				declared, _ := template[name].(map[string]interface{})
+				declaredType, _ := declared["type"].(string)
+				v.validateEntry(entry, nfPath+"."+name, declaredType)
+				continue
+			}
+
+			blockPath := nfPath + "." + name
+			if entry.Tag == "!!null" || !v.expectMapping(entry, blockPath) {
+				continue
+			}
+			if name == "discovery_query" {
+				v.checkKeys(entry, blockPath, discoveryQueryParameters)
+			}
+			for j := 0; j+1 < len(entry.Content); j += 2 {
+				declaredType := "string"
+				if name == "discovery_query" {
+					declaredType = "" // Lists or strings
+				}
+				v.validateEntry(resolveAlias(entry.Content[j+1]), blockPath+"."+entry.Content[j].Value, declaredType)
+			}
+		}
+	}
+}
+
+// validateEntries checks a section of parameter entries
+func (v *configValidator) validateEntries(section *yaml.Node, path string) {
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		name, entry := section.Content[i].Value, resolveAlias(section.Content[i+1])
+		if isConfigComment(name) {
+			continue
+		}
+		v.validateEntry(entry, path+"."+name, "")
+	}
+}
+
+// validateRequestBodies checks a section of request body entries
+func (v *configValidator) validateRequestBodies(section *yaml.Node, path string) {
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		name, body := section.Content[i].Value, resolveAlias(section.Content[i+1])
+		bodyPath := path + "." + name
+		if isConfigComment(name) || !v.expectMapping(body, bodyPath) {
+			continue
+		}
+
+		for key, node := range v.checkKeys(body, bodyPath, requestBodyEntryKeys) {
+			switch key {
+			case "properties":
+				if node.Tag != "!!null" && v.expectMapping(node, bodyPath+".properties") {
+					v.validateEntries(node, bodyPath+".properties")
+				}
+			case "overrides":
+				if node.Tag != "!!null" {
+					v.expectMapping(node, bodyPath+".overrides")
+				}
+			case "file", "content_type":
+				if node.Tag != "!!null" && node.Tag != "!!str" {
+					v.addf(node, bodyPath+"."+key, "expected a string, got %s", describeNode(node))
+				}
+			case "parts":
+				if node.Tag == "!!null" || !v.expectMapping(node, bodyPath+".parts") {
+					continue
+				}
+				for j := 0; j+1 < len(node.Content); j += 2 {
+					partPath := bodyPath + ".parts." + node.Content[j].Value
+					if part := resolveAlias(node.Content[j+1]); v.expectMapping(part, partPath) {
+						v.checkKeys(part, partPath, requestPartKeys)
+					}
+				}
+			}
+		}
+	}
+}
+
+// validateEntry checks a {value, description, type, required, example} entry.
+// A plain value instead of the mapping is accepted, as in older configuration files.
+func (v *configValidator) validateEntry(entry *yaml.Node, path, declaredType string) {
+	if entry.Kind != yaml.MappingNode {
+		v.checkValueType(entry, path, declaredType)
+		return
+	}
+
+	fields := v.checkKeys(entry, path, configEntryKeys)
+
+	if typeNode, exists := fields["type"]; exists {
+		if typeNode.Tag != "!!str" && typeNode.Tag != "!!null" {
+			v.addf(typeNode, path+".type", "expected a type name, got %s", describeNode(typeNode))
+		} else if declaredType == "" {
+			declaredType = typeNode.Value
+		}
+	}
+	if required, exists := fields["required"]; exists && required.Tag != "!!bool" && required.Tag != "!!null" {
+		v.addf(required, path+".required", "expected true or false, got %s", describeNode(required))
+	}
+	if obsolete, exists := fields["obsolete"]; exists && obsolete.Tag != "!!bool" {
+		v.addf(obsolete, path+".obsolete", "expected true or false, got %s", describeNode(obsolete))
+	}
+	if value, exists := fields["value"]; exists {
+		v.checkValueType(value, path+".value", declaredType)
+	}
+}
+
+// checkValueType reports a value that cannot be used as declaredType.
+// Empty values mean "not set" and are always accepted, templates are evaluated per request.
+func (v *configValidator) checkValueType(value *yaml.Node, path, declaredType string) {
+	if value.Tag == "!!null" || (value.Tag == "!!str" && value.Value == "") {
+		return
+	}
+	if value.Tag == "!!str" && hasTemplate(value.Value) {
+		if _, err := compileTemplate(value.Value); err != nil {
+			v.addf(value, path, "%v", err)
+		}
+		return
+	}
+
+	valid := true
+	switch declaredType {
+	case "integer":
+		valid = value.Tag == "!!int"
+	case "number":
+		valid = value.Tag == "!!int" || value.Tag == "!!float"
+	case "boolean":
+		valid = value.Tag == "!!bool"
+	case "string":
+		valid = value.Kind == yaml.ScalarNode
+	}
+
+	if !valid {
+		v.addf(value, path, "expected %s, got %s", declaredType, describeNode(value))
+	}
+}
+
+// expectMapping reports node if it is not a mapping
+func (v *configValidator) expectMapping(node *yaml.Node, path string) bool {
+	if node.Kind == yaml.MappingNode {
+		return true
+	}
+	v.addf(node, path, "expected a mapping, got %s", describeNode(node))
+	return false
+}
+
+// checkKeys reports keys of a mapping that are not in known and returns the known ones
+func (v *configValidator) checkKeys(node *yaml.Node, path string, known []string) map[string]*yaml.Node {
+	fields := make(map[string]*yaml.Node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !containsString(known, key.Value) {
+			if isObsoleteEntry(resolveAlias(node.Content[i+1])) {
+				continue // Kept by a rebuild, no longer used
+			}
+			keyPath := strings.TrimPrefix(path+"."+key.Value, ".")
+			if suggestion := closestName(key.Value, known); suggestion != "" {
+				v.addf(key, keyPath, "unknown key '%s' (did you mean '%s'?)", key.Value, suggestion)
+			} else {
+				v.addf(key, keyPath, "unknown key '%s' (expected one of: %s)", key.Value, strings.Join(known, ", "))
+			}
+			continue
+		}
+		fields[key.Value] = resolveAlias(node.Content[i+1])
+	}
+
+	return fields
+}
+
+// yamlFieldNames returns the yaml keys of a struct type
+func yamlFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isConfigComment checks if a key is one of the generated "# ..." header entries
+func isConfigComment(key string) bool {
+	return strings.HasPrefix(key, "#")
+}
+
+// isObsoleteEntry checks if an entry was marked 'obsolete: true' by a rebuild
+func isObsoleteEntry(node *yaml.Node) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "obsolete" {
+			return node.Content[i+1].Tag == "!!bool" && node.Content[i+1].Value == "true"
+		}
+	}
+	return false
+}
+
+// resolveAlias follows YAML aliases (*anchor) to the anchored node
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// describeNode describes a node for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+
+	kind := strings.TrimPrefix(node.Tag, "!!")
+	switch kind {
+	case "str":
+		kind = "string"
+	case "int":
+		kind = "integer"
+	case "bool":
+		kind = "boolean"
+	case "float":
+		kind = "number"
+	}
+	return fmt.Sprintf("%s %q", kind, node.Value)
+}
+
+// containsString checks if list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// closestName returns the candidate within a small edit distance of name, if any
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if strings.EqualFold(name, candidate) {
+			return candidate
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+	"gopkg.in/yaml.v3"
+)
+
+// maxMergeSummaryPaths limits the entries listed per change kind in the merge summary
+const maxMergeSummaryPaths = 20
+
+// configSection is one user_inputs section of the generated and of the existing configuration
+type configSection struct {
+	path      string
+	generated map[string]interface{}
+	existing  map[string]interface{}
+}
+
+// configMergeSummary records what a merge changed
+type configMergeSummary struct {
+	nfFilter string
+	kept     int      // Entered values carried over
+	added    []string // Entries the existing file did not have
+	obsolete []string // Entries no longer generated, marked obsolete
+	retained []string // Entries outside the NF filter, kept unchanged
+}
+
+// mergeExistingConfiguration backs up an existing configuration file and, unless fresh is
+// set, carries its entered values over into the generated configuration
+func mergeExistingConfiguration(config *types.ConfigurationFile, filename, nfFilter string, fresh bool) error {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", filename, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filename, err)
+	}
+	fmt.Printf("📄 Previous configuration backed up: %s\n", backup)
+
+	if fresh {
+		return nil
+	}
+
+	var existing types.ConfigurationFile
+	if err := yaml.Unmarshal(data, &existing); err != nil {
+		fmt.Printf("⚠️  Cannot merge %s, writing a fresh configuration: %v\n", filename, err)
+		return nil
+	}
+
+	summary := &configMergeSummary{nfFilter: nfFilter}
+	summary.mergeUserInputs(&config.UserInputs, existing.UserInputs)
+	summary.print(filename)
+
+	// Profiles hold only entered values, they are kept as they are
+	config.Profiles = existing.Profiles
+	if len(config.Profiles) > 0 {
+		fmt.Printf("   %d profiles kept\n", len(config.Profiles))
+	}
+	return nil
+}
+
+// mergeUserInputs merges every section of the existing user inputs into the generated ones
+func (s *configMergeSummary) mergeUserInputs(generated *types.UserInputSection, existing types.UserInputSection) {
+	s.mergeSections([]configSection{
+		{"global_settings", generated.GlobalSettings, existing.GlobalSettings},
+	}, s.mergeValue)
+
+	s.mergeNFSettings(generated.NFSettings, existing.NFSettings)
+
+	// Entries move between the common and API-specific sections when their usage changes
+	s.mergeSections([]configSection{
+		{"common_parameters", generated.CommonParameters, existing.CommonParameters},
+		{"api_specific_parameters", generated.APISpecificParameters, existing.APISpecificParameters},
+	}, s.mergeValue)
+
+	s.mergeSections([]configSection{
+		{"common_request_bodies", generated.CommonRequestBodies, existing.CommonRequestBodies},
+		{"api_specific_request_bodies", generated.APISpecificRequestBodies, existing.APISpecificRequestBodies},
+	}, s.mergeRequestBody)
+}
+
+// mergeSections matches the entries of sections by name. Generated entries take the existing
+// values, existing entries that are no longer generated are kept in their section as obsolete.
+func (s *configMergeSummary) mergeSections(sections []configSection, merge func(path string, generated, existing interface{}) interface{}) {
+	existingByName := make(map[string]interface{})
+	for _, section := range sections {
+		for name, entry := range section.existing {
+			if !isConfigComment(name) {
+				existingByName[name] = entry
+			}
+		}
+	}
+
+	generatedNames := make(map[string]bool)
+	for _, section := range sections {
+		for _, name := range sortedMapKeys(section.generated) {
+			if isConfigComment(name) {
+				continue
+			}
+			generatedNames[name] = true
+
+			path := section.path + "." + name
+			if existing, exists := existingByName[name]; exists {
+				section.generated[name] = merge(path, section.generated[name], existing)
+			} else {
+				s.added = append(s.added, path)
+			}
+		}
+	}
+
+	for _, section := range sections {
+		for name, entry := range section.existing {
+			if isConfigComment(name) || generatedNames[name] || section.generated == nil {
+				continue
+			}
+			if isAPIRequestBodyKey(name) {
+				section.generated[name] = entry // Added by the user for one API
+				continue
+			}
+			section.generated[name] = s.markObsolete(section.path+"."+name, entry)
+		}
+	}
+}
+
+// mergeValue keeps the existing 'value' of a {value, description, ...} entry.
+// Descriptions, types and examples are taken from the specifications.
+func (s *configMergeSummary) mergeValue(path string, generated, existing interface{}) interface{} {
+	entry, ok := generated.(map[string]interface{})
+	if !ok {
+		return generated
+	}
+
+	value, hasValue := existing, existing != nil
+	if existingEntry, ok := existing.(map[string]interface{}); ok {
+		value, hasValue = existingEntry["value"]
+	}
+	if !hasValue {
+		return generated
+	}
+
+	if !IsEmptyValue(value) && !reflect.DeepEqual(value, entry["value"]) {
+		s.kept++
+	}
+	entry["value"] = value
+	return entry
+}
+
+// mergeRequestBody keeps the body file, overrides and property values of an existing request body entry
+func (s *configMergeSummary) mergeRequestBody(path string, generated, existing interface{}) interface{} {
+	body, ok := generated.(map[string]interface{})
+	existingBody, existingOK := existing.(map[string]interface{})
+	if !ok || !existingOK {
+		return generated
+	}
+
+	if file, ok := existingBody["file"]; ok {
+		body["file"] = file
+		s.kept++
+	}
+
+	if overrides, ok := existingBody["overrides"].(map[string]interface{}); ok && len(overrides) > 0 {
+		body["overrides"] = overrides
+		s.kept += len(overrides)
+	}
+
+	existingProperties, _ := existingBody["properties"].(map[string]interface{})
+	properties, _ := body["properties"].(map[string]interface{})
+	if properties == nil && len(existingProperties) > 0 {
+		properties = make(map[string]interface{})
+		body["properties"] = properties
+	}
+	s.mergeSections([]configSection{{path + ".properties", properties, existingProperties}}, s.mergeValue)
+
+	return body
+}
+
+// mergeNFSettings keeps the settings, custom headers and discovery query of existing NFs
+func (s *configMergeSummary) mergeNFSettings(generated, existing map[string]map[string]interface{}) {
+	for nf, settings := range existing {
+		path := "nf_settings." + nf
+		if settings == nil {
+			continue
+		}
+
+		if _, exists := generated[nf]; !exists {
+			for name, entry := range settings {
+				if block, ok := entry.(map[string]interface{}); ok && containsString(nfSettingBlocks, name) {
+					for key, blockEntry := range block {
+						block[key] = s.markObsolete(path+"."+name+"."+key, blockEntry)
+					}
+					continue
+				}
+				settings[name] = s.markObsolete(path+"."+name, entry)
+			}
+			generated[nf] = settings
+			continue
+		}
+
+		s.mergeSections([]configSection{{path, generated[nf], settings}}, s.mergeValue)
+
+		for _, blockName := range nfSettingBlocks {
+			existingBlock, _ := settings[blockName].(map[string]interface{})
+			block, _ := generated[nf][blockName].(map[string]interface{})
+			if block == nil {
+				continue
+			}
+			for name, entry := range existingBlock {
+				if generatedEntry, exists := block[name]; exists {
+					block[name] = s.mergeValue(path+"."+blockName+"."+name, generatedEntry, entry)
+				} else {
+					block[name] = entry // Added by the user
+				}
+			}
+		}
+	}
+}
+
+// markObsolete marks an entry that is no longer generated. With an NF filter the entry may
+// belong to an NF that was not generated, so it is kept unchanged instead.
+func (s *configMergeSummary) markObsolete(path string, entry interface{}) interface{} {
+	if s.nfFilter != "" {
+		s.retained = append(s.retained, path)
+		return entry
+	}
+
+	existingEntry, ok := entry.(map[string]interface{})
+	if !ok {
+		existingEntry = map[string]interface{}{"value": entry}
+	}
+	if existingEntry["obsolete"] != true {
+		s.obsolete = append(s.obsolete, path)
+	}
+	existingEntry["obsolete"] = true
+	return existingEntry
+}
+
+// print shows the changes of the merge
+func (s *configMergeSummary) print(filename string) {
+	fmt.Printf("🔄 Merged with existing %s: %d entered values kept, %d entries added, %d entries marked obsolete\n",
+		filename, s.kept, len(s.added), len(s.obsolete))
+	printConfigPaths("+", s.added)
+	printConfigPaths("-", s.obsolete)
+	if len(s.retained) > 0 {
+		fmt.Printf("   %d entries not generated for NF %s were kept unchanged\n", len(s.retained), s.nfFilter)
+	}
+}
+
+// printConfigPaths lists configuration paths in sorted order
+func printConfigPaths(marker string, paths []string) {
+	sort.Strings(paths)
+	for i, path := range paths {
+		if i == maxMergeSummaryPaths {
+			fmt.Printf("   ... %d more\n", len(paths)-i)
+			break
+		}
+		fmt.Printf("   %s %s\n", marker, path)
+	}
+}
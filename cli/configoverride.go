@@ -0,0 +1,415 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devuk0204/ctrlbench/types"
+	"gopkg.in/yaml.v3"
+)
+
+// envOverridePrefix starts the environment variables layered on top of the configuration file
+const envOverridePrefix = "CTRLBENCH_"
+
+// siblingSections are searched when an override names an entry of the other section.
+// Entries move between the common and API-specific sections when the specifications change.
+var siblingSections = map[string]string{
+	"common_parameters":           "api_specific_parameters",
+	"api_specific_parameters":     "common_parameters",
+	"common_request_bodies":       "api_specific_request_bodies",
+	"api_specific_request_bodies": "common_request_bodies",
+}
+
+// configurationSource is the configuration file selected with -c, the profile selected
+// with -profile and the -set overrides
+var configurationSource = struct {
+	file    string
+	profile string
+	sets    []types.ConfigOverride
+}{file: ConfigurationFileName}
+
+// SetConfigurationSource selects the configuration file, its profile (CTRLBENCH_PROFILE
+// when empty) and the -set path=value overrides of every command that reads the configuration
+func SetConfigurationSource(file, profile string, sets []string) error {
+	overrides := make([]types.ConfigOverride, 0, len(sets))
+	for _, set := range sets {
+		path, value, found := strings.Cut(set, "=")
+		if !found || strings.TrimSpace(path) == "" {
+			return fmt.Errorf("invalid -set '%s', expected path=value (e.g. user_inputs.global_settings.nrf_url=http://10.0.0.1:8000)", set)
+		}
+		overrides = append(overrides, types.ConfigOverride{Path: strings.TrimSpace(path), Value: value, Source: "-set " + path})
+	}
+
+	if file != "" {
+		configurationSource.file = file
+	}
+	if profile == "" {
+		profile = os.Getenv(profileEnvVariable)
+	}
+	configurationSource.profile = profile
+	configurationSource.sets = overrides
+	return nil
+}
+
+// ConfigurationPath returns the configuration file selected with -c
+func ConfigurationPath() string {
+	return configurationSource.file
+}
+
+// ConfigurationProfile returns the profile selected with -profile or CTRLBENCH_PROFILE
+func ConfigurationProfile() string {
+	return configurationSource.profile
+}
+
+// ConfigurationOverrides returns the overrides in the order they are applied:
+// environment variables first, then -set flags
+func ConfigurationOverrides() []types.ConfigOverride {
+	return append(EnvironmentOverrides(os.Environ()), configurationSource.sets...)
+}
+
+// LoadEffectiveConfiguration loads the selected configuration file with the profile and
+// the overrides applied
+func LoadEffectiveConfiguration() (*types.ConfigurationFile, error) {
+	filename := ConfigurationPath()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	root, _, err := parseConfigurationDocument(filename, data, ConfigurationProfile(), ConfigurationOverrides())
+	if err != nil {
+		return nil, err
+	}
+
+	var config types.ConfigurationFile
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &config, nil
+}
+
+// EnvironmentOverrides maps environment variables to configuration overrides:
+//
+//	CTRLBENCH_GLOBAL_<SETTING>   → user_inputs.global_settings.<setting>
+//	CTRLBENCH_NF_<NF>_<SETTING>  → user_inputs.nf_settings.<NF>.<setting>
+//	CTRLBENCH_PARAM_<NAME>       → user_inputs.common_parameters.<name> (or api_specific_parameters)
+//
+// Names are matched ignoring case, '_' also matches '-'.
+func EnvironmentOverrides(environ []string) []types.ConfigOverride {
+	var overrides []types.ConfigOverride
+
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		key, ok := strings.CutPrefix(name, envOverridePrefix)
+		if !ok {
+			continue
+		}
+
+		var path string
+		switch {
+		case strings.HasPrefix(key, "GLOBAL_"):
+			path = "user_inputs.global_settings." + strings.ToLower(strings.TrimPrefix(key, "GLOBAL_"))
+		case strings.HasPrefix(key, "NF_"):
+			nf, setting, found := strings.Cut(strings.TrimPrefix(key, "NF_"), "_")
+			if !found {
+				continue
+			}
+			path = "user_inputs.nf_settings." + nf + "." + strings.ToLower(setting)
+		case strings.HasPrefix(key, "PARAM_"):
+			path = "user_inputs.common_parameters." + strings.ToLower(strings.TrimPrefix(key, "PARAM_"))
+		default:
+			continue
+		}
+		overrides = append(overrides, types.ConfigOverride{Path: path, Value: value, Source: "env " + name})
+	}
+
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Source < overrides[j].Source })
+	return overrides
+}
+
+// applyOverrides sets the overrides in the document in order, later ones win
+func (v *configValidator) applyOverrides(root *yaml.Node, overrides []types.ConfigOverride) {
+	for _, override := range overrides {
+		if err := v.applyOverride(root, override); err != nil {
+			v.errs = append(v.errs, types.ConfigError{File: override.Source, Path: override.Path, Message: err.Error()})
+		}
+	}
+}
+
+// applyOverride walks the path of an override, creating missing mappings, and sets its value.
+// A path naming a {value, ...} entry sets the entry's value.
+func (v *configValidator) applyOverride(root *yaml.Node, override types.ConfigOverride) error {
+	keys := splitOverridePath(override.Path)
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("cannot apply to %s", describeNode(root))
+	}
+
+	parent, node := root, root
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set '%s' inside %s", key, describeNode(node))
+		}
+		parent = node
+
+		index := findConfigKey(node, key)
+		if index < 0 && i == 2 && keys[0] == "user_inputs" && siblingSections[keys[1]] != "" {
+			// Entries of parameter and body sections are found in either section
+			if sibling := lookupConfigNode(root, "user_inputs", siblingSections[keys[1]]); sibling != nil {
+				if siblingIndex := findConfigKey(sibling, key); siblingIndex >= 0 {
+					parent, node, index = sibling, sibling, siblingIndex
+				}
+			}
+			if index < 0 {
+				kind := "parameter"
+				if strings.HasSuffix(keys[1], "request_bodies") {
+					kind = "request body"
+				}
+				return fmt.Errorf("no %s '%s' in the configuration", kind, key)
+			}
+		}
+
+		if i == len(keys)-1 {
+			return v.setOverrideValue(parent, index, key, keys, override)
+		}
+
+		if index < 0 {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+			valueNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			v.origins[keyNode] = override.Source
+			node.Content = append(node.Content, keyNode, valueNode)
+			index = len(node.Content) - 2
+		}
+		node = resolveAlias(node.Content[index+1])
+		if node.Tag == "!!null" {
+			// Empty section or entry, e.g. "overrides:" without values
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		}
+	}
+
+	return nil
+}
+
+// setOverrideValue sets the last key of an override path in parent (index -1 when missing)
+func (v *configValidator) setOverrideValue(parent *yaml.Node, index int, key string, keys []string, override types.ConfigOverride) error {
+	underOverrides := len(keys) > 1 && keys[len(keys)-2] == "overrides"
+	entryField := containsString(configEntryKeys, key) || containsString(requestBodyEntryKeys, key)
+
+	if index < 0 {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		v.origins[keyNode] = override.Source
+		value := v.overrideValueNode(override, overrideIsString(nil, keys))
+		if !underOverrides && !entryField {
+			// New entry, e.g. a custom header
+			valueKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "value"}
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{valueKey, value}}
+			v.origins[valueKey], v.origins[value] = override.Source, override.Source
+		}
+		parent.Content = append(parent.Content, keyNode, value)
+		return nil
+	}
+
+	target := resolveAlias(parent.Content[index+1])
+	if target.Kind == yaml.MappingNode && !underOverrides {
+		// {value, description, ...} entry
+		valueIndex := findConfigKey(target, "value")
+		if valueIndex < 0 {
+			valueKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "value"}
+			v.origins[valueKey] = override.Source
+			target.Content = append(target.Content, valueKey, nil)
+			valueIndex = len(target.Content) - 2
+		}
+		target.Content[valueIndex+1] = v.overrideValueNode(override, overrideIsString(target, keys))
+		return nil
+	}
+
+	parent.Content[index+1] = v.overrideValueNode(override, overrideIsString(parent, keys) || target.Tag == "!!str" && target.Value != "")
+	return nil
+}
+
+// overrideIsString checks if an override value is kept as a string instead of parsed as YAML:
+// the entry declares type string, already holds a string, or is a custom header
+func overrideIsString(entry *yaml.Node, keys []string) bool {
+	if containsString(keys, "custom_headers") {
+		return true
+	}
+	if entry == nil || entry.Kind != yaml.MappingNode {
+		return false
+	}
+	if index := findConfigKey(entry, "type"); index >= 0 && entry.Content[index+1].Tag == "!!str" {
+		return entry.Content[index+1].Value == "string"
+	}
+	if index := findConfigKey(entry, "value"); index >= 0 && entry.Content[index+1] != nil {
+		value := entry.Content[index+1]
+		return value.Tag == "!!str" && value.Value != ""
+	}
+	return false
+}
+
+// overrideValueNode converts the value of an override to a node, recording its source
+func (v *configValidator) overrideValueNode(override types.ConfigOverride, asString bool) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: override.Value}
+	if !asString && !hasTemplate(override.Value) {
+		var doc yaml.Node
+		// Digits with leading zeros (MCC, MNC, MSIN) and templates stay strings
+		if err := yaml.Unmarshal([]byte(override.Value), &doc); err == nil && len(doc.Content) > 0 && !hasLeadingZero(doc.Content[0]) {
+			node = doc.Content[0]
+		}
+	}
+
+	var record func(n *yaml.Node)
+	record = func(n *yaml.Node) {
+		n.Line, n.Column = 0, 0
+		v.origins[n] = override.Source
+		for _, child := range n.Content {
+			record(child)
+		}
+	}
+	record(node)
+	return node
+}
+
+// hasLeadingZero checks if a YAML integer was written with leading zeros
+func hasLeadingZero(node *yaml.Node) bool {
+	digits := strings.TrimLeft(node.Value, "+-")
+	return node.Tag == "!!int" && len(digits) > 1 && digits[0] == '0' && !strings.ContainsAny(digits, "xXoObB")
+}
+
+// splitOverridePath splits a dotted override path. The user_inputs prefix is optional and
+// everything after a request body's 'overrides' key is one JSON path.
+func splitOverridePath(path string) []string {
+	keys := strings.Split(strings.TrimPrefix(path, "."), ".")
+	if keys[0] != "user_inputs" {
+		keys = append([]string{"user_inputs"}, keys...)
+	}
+
+	for i := 3; i < len(keys)-1; i++ {
+		if keys[i] == "overrides" && strings.HasSuffix(keys[1], "request_bodies") {
+			return append(keys[:i+1], strings.Join(keys[i+1:], "."))
+		}
+	}
+	return keys
+}
+
+// findConfigKey returns the index of key in a mapping, matching case-insensitively and
+// '_' with '-' when there is no exact match, or -1
+func findConfigKey(node *yaml.Node, key string) int {
+	loose := -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		if name == key {
+			return i
+		}
+		if loose < 0 && normalizeConfigKey(name) == normalizeConfigKey(key) {
+			loose = i
+		}
+	}
+	return loose
+}
+
+// normalizeConfigKey folds the differences environment variable names cannot express
+func normalizeConfigKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
+
+// lookupConfigNode follows keys through mappings
+func lookupConfigNode(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		index := findConfigKey(node, key)
+		if index < 0 {
+			return nil
+		}
+		node = resolveAlias(node.Content[index+1])
+	}
+	return node
+}
+
+// collectValues lists the non-empty values below node with their origin.
+// Descriptions, types and examples are not values, obsolete entries are skipped.
+func (v *configValidator) collectValues(node *yaml.Node, path string, values *[]types.ConfigValue) {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		v.addValue(node, path, values)
+		return
+	}
+	if isObsoleteEntry(node) {
+		return
+	}
+	if index := findConfigKey(node, "value"); index >= 0 && node.Content[index].Value == "value" {
+		v.addValue(resolveAlias(node.Content[index+1]), path, values)
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, child := node.Content[i].Value, resolveAlias(node.Content[i+1])
+		childPath := strings.TrimPrefix(path+"."+key, ".")
+		switch {
+		case isConfigComment(key), path == "" && key == "profiles":
+			// Values of the selected profile are listed where they apply
+		case key == "overrides" && child.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(child.Content); j += 2 {
+				v.addValue(resolveAlias(child.Content[j+1]), childPath+"."+child.Content[j].Value, values)
+			}
+		case child.Kind != yaml.MappingNode && (containsString(configEntryKeys, key) || containsString(requestBodyEntryKeys, key)):
+		default:
+			v.collectValues(child, childPath, values)
+		}
+	}
+}
+
+// addValue records a non-empty value
+func (v *configValidator) addValue(node *yaml.Node, path string, values *[]types.ConfigValue) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil || IsEmptyValue(value) {
+		return
+	}
+
+	source, ok := v.origins[node]
+	if !ok {
+		source = fmt.Sprintf("%s:%d", v.file, node.Line)
+		if profile, fromProfile := v.profiles[node]; fromProfile {
+			source += " (profile " + profile + ")"
+		}
+	}
+	*values = append(*values, types.ConfigValue{Path: path, Value: value, Source: source})
+}
+
+// PrintConfigValues prints configuration values in text or json format
+func PrintConfigValues(values []types.ConfigValue, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	case "text", "":
+	default:
+		return fmt.Errorf("unknown config output format '%s' (text, json)", format)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PATH\tVALUE\tSOURCE")
+	for _, value := range values {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", value.Path, formatConfigValue(value.Value), value.Source)
+	}
+	table.Flush()
+	fmt.Printf("\n%d values set\n", len(values))
+	return nil
+}
+
+// formatConfigValue renders a value on one line, lists and objects as JSON
+func formatConfigValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(normalizeJSONValue(value))
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
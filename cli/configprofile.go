@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileEnvVariable selects a profile when -profile is not given
+const profileEnvVariable = "CTRLBENCH_PROFILE"
+
+// profileSections are the user_inputs sections a profile can set
+var profileSections = []string{"global_settings", "nf_settings"}
+
+// applyProfile layers the settings of a named profile over user_inputs
+func (v *configValidator) applyProfile(root *yaml.Node, name string) {
+	profiles := lookupConfigNode(root, "profiles")
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		v.addf(root, "profiles", "profile '%s' selected but the file defines no profiles", name)
+		return
+	}
+
+	var profile *yaml.Node
+	var names []string
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		names = append(names, profiles.Content[i].Value)
+		if profiles.Content[i].Value == name {
+			profile = resolveAlias(profiles.Content[i+1])
+		}
+	}
+	if profile == nil {
+		v.addf(profiles, "profiles", "profile '%s' not found (available: %s)", name, strings.Join(names, ", "))
+		return
+	}
+
+	userInputs := lookupConfigNode(root, "user_inputs")
+	if userInputs == nil || userInputs.Kind != yaml.MappingNode || profile.Kind != yaml.MappingNode {
+		return // Reported by validateRoot
+	}
+
+	for _, section := range profileSections {
+		settings := lookupConfigNode(profile, section)
+		if settings == nil || settings.Kind != yaml.MappingNode {
+			continue
+		}
+		v.markProfileNodes(settings, name)
+
+		index := findConfigKey(userInputs, section)
+		if index < 0 {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: section}
+			userInputs.Content = append(userInputs.Content, keyNode, settings)
+			continue
+		}
+		base := resolveAlias(userInputs.Content[index+1])
+		if base.Kind != yaml.MappingNode {
+			userInputs.Content[index+1] = settings
+			continue
+		}
+		mergeConfigNodes(base, settings)
+	}
+}
+
+// mergeConfigNodes merges the keys of overlay into base, keeping what overlay does not set.
+// A plain value in overlay sets the value of a {value, description, ...} entry of base.
+func mergeConfigNodes(base, overlay *yaml.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], resolveAlias(overlay.Content[i+1])
+
+		index := findConfigKey(base, key.Value)
+		if index < 0 {
+			base.Content = append(base.Content, key, value)
+			continue
+		}
+
+		current := resolveAlias(base.Content[index+1])
+		switch {
+		case current.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeConfigNodes(current, value)
+		case current.Kind == yaml.MappingNode && findConfigKey(current, "value") >= 0:
+			current.Content[findConfigKey(current, "value")+1] = value
+		default:
+			base.Content[index+1] = value
+		}
+	}
+}
+
+// markProfileNodes records the nodes of a profile so values show where they came from
+func (v *configValidator) markProfileNodes(node *yaml.Node, name string) {
+	v.profiles[node] = name
+	for _, child := range node.Content {
+		v.markProfileNodes(resolveAlias(child), name)
+	}
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// requestPartKeys are the fields of a binary part entry
+var requestPartKeys = []string{"file", "hex", "content_type", "content_id"}
+
+// jsonPatchOperations are the operations of RFC 6902
+var jsonPatchOperations = []string{"add", "remove", "replace", "move", "copy", "test"}
+
+// multipartBoundary separates the parts of multipart/related bodies
+const multipartBoundary = "ctrlbench-multipart-boundary"
+
+// resolveRequestContent picks the media type an API body is sent as and shapes the body for it.
+// The content_type of the API's entry wins over the media type the operation prefers.
+func resolveRequestContent(apiInfo *types.APIListEntry, apiRef string, userInputs types.UserInputSection, body interface{}) (string, interface{}, []types.RequestPart, error) {
+	_, apiBody := lookupAPIRequestBody(userInputs.APISpecificRequestBodies, apiRef, apiInfo.Key)
+
+	contentType := strings.ToLower(cfgText(apiBody["content_type"]))
+	switch {
+	case contentType == "" && len(apiInfo.ContentTypes) > 0:
+		contentType = apiInfo.ContentTypes[0]
+	case contentType == "" && body == nil:
+		return "", nil, nil, nil
+	case contentType == "":
+		contentType = types.ContentTypeJSON
+	case len(apiInfo.ContentTypes) > 0 && !containsString(apiInfo.ContentTypes, contentType):
+		return "", body, nil, fmt.Errorf("content_type '%s' is not declared by the operation (declared: %s)",
+			contentType, strings.Join(apiInfo.ContentTypes, ", "))
+	}
+
+	switch {
+	case contentType == types.ContentTypeJSONPatch:
+		patch, err := toJSONPatch(body)
+		return contentType, patch, nil, err
+	case contentType == types.ContentTypeMultipart:
+		parts, err := loadRequestParts(apiInfo.RequestParts, apiBody["parts"])
+		if err == nil {
+			err = checkContentIDs(body, parts, apiInfo.RequestParts)
+		}
+		return contentType, body, parts, err
+	case !strings.Contains(contentType, "json"):
+		return "", body, nil, fmt.Errorf("content type '%s' is not supported (JSON types, %s, %s)",
+			contentType, types.ContentTypeJSONPatch, types.ContentTypeMultipart)
+	}
+	return contentType, body, nil, nil
+}
+
+// toJSONPatch shapes a body into a JSON Patch array (RFC 6902). A list of operations is checked,
+// an object of path → value pairs becomes replace operations. Dotted paths become JSON pointers.
+func toJSONPatch(body interface{}) (interface{}, error) {
+	switch v := body.(type) {
+	case []interface{}:
+		patch := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			operation, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json-patch item %d: expected an object with op and path", i)
+			}
+			normalized, err := normalizePatchOperation(operation)
+			if err != nil {
+				return nil, fmt.Errorf("json-patch item %d: %w", i, err)
+			}
+			patch = append(patch, normalized)
+		}
+		return patch, nil
+	case map[string]interface{}:
+		if _, isOperation := v["op"]; isOperation {
+			return toJSONPatch([]interface{}{v})
+		}
+		patch := make([]interface{}, 0, len(v))
+		for _, path := range sortedMapKeys(v) {
+			pointer, err := jsonPointer(path)
+			if err != nil {
+				return nil, err
+			}
+			patch = append(patch, map[string]interface{}{"op": "replace", "path": pointer, "value": v[path]})
+		}
+		return patch, nil
+	case nil:
+		return nil, fmt.Errorf("json-patch body is empty, configure a file or overrides for the API")
+	}
+	return nil, fmt.Errorf("json-patch body must be a list of operations or an object of path → value")
+}
+
+// normalizePatchOperation checks one JSON Patch operation and converts its paths to JSON pointers
+func normalizePatchOperation(operation map[string]interface{}) (map[string]interface{}, error) {
+	op, _ := operation["op"].(string)
+	if !containsString(jsonPatchOperations, op) {
+		return nil, fmt.Errorf("op '%v' is not one of %s", operation["op"], strings.Join(jsonPatchOperations, ", "))
+	}
+
+	normalized := make(map[string]interface{}, len(operation))
+	for key, value := range operation {
+		normalized[key] = value
+	}
+	for _, key := range []string{"path", "from"} {
+		path, ok := operation[key].(string)
+		if !ok {
+			if key == "path" || op == "move" || op == "copy" {
+				return nil, fmt.Errorf("%s needs a '%s'", op, key)
+			}
+			continue
+		}
+		pointer, err := jsonPointer(path)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = pointer
+	}
+	if _, hasValue := operation["value"]; !hasValue && (op == "add" || op == "replace" || op == "test") {
+		return nil, fmt.Errorf("%s needs a 'value'", op)
+	}
+	return normalized, nil
+}
+
+// jsonPointer converts a JSON pointer or dotted path into a JSON pointer
+func jsonPointer(path string) (string, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String(), nil
+}
+
+// loadRequestParts reads the binary parts configured for a multipart/related body.
+// Parts that are not configured are left out, they are optional in the 3GPP APIs.
+func loadRequestParts(declared []types.BodyPart, config interface{}) ([]types.RequestPart, error) {
+	configured, _ := config.(map[string]interface{})
+
+	declaredParts := make(map[string]types.BodyPart)
+	var binaryNames []string
+	for _, part := range declared {
+		declaredParts[part.Name] = part
+		if part.Binary {
+			binaryNames = append(binaryNames, part.Name)
+		}
+	}
+
+	var parts []types.RequestPart
+	for _, name := range sortedMapKeys(configured) {
+		entry, _ := configured[name].(map[string]interface{})
+		if len(declared) > 0 && !containsString(binaryNames, name) {
+			return nil, fmt.Errorf("parts.%s: not a binary part of the operation (binary parts: %s)", name, strings.Join(binaryNames, ", "))
+		}
+
+		part := types.RequestPart{
+			Name:        name,
+			ContentType: cfgText(entry["content_type"]),
+			ContentID:   cfgText(entry["content_id"]),
+		}
+		if part.ContentType == "" {
+			part.ContentType = declaredParts[name].ContentType
+		}
+		if part.ContentType == "" {
+			part.ContentType = "application/octet-stream"
+		}
+		if part.ContentID == "" {
+			part.ContentID = name
+		}
+
+		switch file, hexData := cfgText(entry["file"]), cfgText(entry["hex"]); {
+		case file != "":
+			data, err := os.ReadFile(resolveConfigPath(file))
+			if err != nil {
+				return nil, fmt.Errorf("parts.%s: %w", name, err)
+			}
+			part.Data = data
+		case hexData != "":
+			data, err := hex.DecodeString(strings.Join(strings.Fields(hexData), ""))
+			if err != nil {
+				return nil, fmt.Errorf("parts.%s.hex: %w", name, err)
+			}
+			part.Data = data
+		default:
+			return nil, fmt.Errorf("parts.%s: set 'file' or 'hex'", name)
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// checkContentIDs fails when the body references, by a RefToBinaryData contentId, a binary part
+// that is not configured. The receiving NF would miss the N1/N2 message it expects.
+func checkContentIDs(body interface{}, parts []types.RequestPart, declared []types.BodyPart) error {
+	configured := make([]string, 0, len(parts))
+	for _, part := range parts {
+		configured = append(configured, part.ContentID)
+	}
+
+	var binaryNames []string
+	for _, part := range declared {
+		if part.Binary {
+			binaryNames = append(binaryNames, part.Name)
+		}
+	}
+
+	for _, contentID := range bodyContentIDs(body) {
+		if containsString(configured, contentID) || strings.Contains(contentID, "{{") {
+			continue
+		}
+		if containsString(binaryNames, contentID) {
+			return fmt.Errorf("body references contentId '%s' but the part is not configured, set parts.%s.file or parts.%s.hex",
+				contentID, contentID, contentID)
+		}
+		return fmt.Errorf("body references contentId '%s' but no configured part has it, set the content_id of one of the binary parts (%s)",
+			contentID, strings.Join(binaryNames, ", "))
+	}
+	return nil
+}
+
+// bodyContentIDs collects the contentId values of the RefToBinaryData objects in a body
+func bodyContentIDs(body interface{}) []string {
+	var ids []string
+	switch v := body.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedMapKeys(v) {
+			if id, ok := v[key].(string); ok && key == "contentId" {
+				ids = append(ids, id)
+				continue
+			}
+			ids = append(ids, bodyContentIDs(v[key])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			ids = append(ids, bodyContentIDs(item)...)
+		}
+	}
+	return ids
+}
+
+// encodeMultipart builds a multipart/related body of the JSON data and the binary parts
+// and returns it with the Content-Type header that names its boundary
+func encodeMultipart(jsonData []byte, parts []types.RequestPart) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(multipartBoundary); err != nil {
+		return nil, "", err
+	}
+
+	root, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {types.ContentTypeJSON}})
+	if err != nil {
+		return nil, "", err
+	}
+	root.Write(jsonData)
+
+	for _, part := range parts {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {part.ContentType},
+			"Content-Id":   {part.ContentID},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		w.Write(part.Data)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	contentType := mime.FormatMediaType(types.ContentTypeMultipart, map[string]string{
+		"boundary": multipartBoundary,
+		"type":     types.ContentTypeJSON,
+	})
+	return buf.Bytes(), contentType, nil
+}
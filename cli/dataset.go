@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultZipfSkew is the Zipf exponent when dataset_zipf_s is not set
+const defaultZipfSkew = 1.2
+
+// subscriberDataset draws the subscribers of a dataset file in the configured order
+type subscriberDataset struct {
+	file    string
+	order   string
+	columns []string
+	rows    []types.Subscriber
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	zipf *rand.Zipf
+	next int
+	perm []int
+
+	peeked types.Subscriber // Drawn ahead by peek, returned by the next draw
+}
+
+// LoadSubscriberDataset reads the dataset of global_settings.dataset_file, nil when none is set
+func LoadSubscriberDataset(globalSettings map[string]interface{}) (*subscriberDataset, error) {
+	file := cfgText(globalSettings["dataset_file"])
+	if file == "" {
+		return nil, nil
+	}
+
+	rows, err := readSubscriberFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dataset %s holds no subscribers", file)
+	}
+
+	d := &subscriberDataset{file: file, order: strings.ToLower(cfgText(globalSettings["dataset_order"])), rows: rows}
+	if d.order == "" {
+		d.order = types.DatasetSequential
+	}
+
+	seed, _ := cfgNumber(globalSettings["dataset_seed"])
+	if seed == 0 {
+		seed = float64(time.Now().UnixNano())
+	}
+	d.rand = rand.New(rand.NewPCG(uint64(seed), 0))
+
+	switch d.order {
+	case types.DatasetSequential, types.DatasetUniform:
+	case types.DatasetRandom:
+		d.perm = d.rand.Perm(len(rows))
+	case types.DatasetZipf:
+		skew, ok := cfgNumber(globalSettings["dataset_zipf_s"])
+		if !ok || skew == 0 {
+			skew = defaultZipfSkew
+		}
+		if skew <= 1 {
+			return nil, fmt.Errorf("global_settings.dataset_zipf_s: must be greater than 1, got %g", skew)
+		}
+		d.zipf = rand.NewZipf(d.rand, skew, 1, uint64(len(rows)-1))
+	default:
+		return nil, fmt.Errorf("global_settings.dataset_order: unknown order '%s' (%s)", d.order, strings.Join(types.DatasetOrders, ", "))
+	}
+
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				d.columns = append(d.columns, column)
+			}
+		}
+	}
+	sort.Strings(d.columns)
+	return d, nil
+}
+
+// readSubscriberFile reads a CSV file with a header row, or a YAML list of subscribers.
+// Relative paths are relative to the configuration file.
+func readSubscriberFile(file string) ([]types.Subscriber, error) {
+	data, err := os.ReadFile(resolveConfigPath(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return parseSubscriberYAML(file, data)
+	case ".csv":
+		return parseSubscriberCSV(file, data)
+	}
+	return nil, fmt.Errorf("dataset %s: expected a .csv, .yaml or .yml file", file)
+}
+
+// parseSubscriberCSV reads a CSV dataset, the first row names the columns
+func parseSubscriberCSV(file string, data []byte) ([]types.Subscriber, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("dataset %s: %w", file, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]types.Subscriber, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(types.Subscriber, len(header))
+		for i, column := range header {
+			row[normalizeDatasetColumn(column)] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, completeSubscriber(row))
+	}
+	return rows, nil
+}
+
+// parseSubscriberYAML reads a YAML dataset: a list of subscribers, or a 'subscribers' key holding one
+func parseSubscriberYAML(file string, data []byte) ([]types.Subscriber, error) {
+	var list []map[string]interface{}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		var doc struct {
+			Subscribers []map[string]interface{} `yaml:"subscribers"`
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("dataset %s: %w", file, err)
+		}
+		list = doc.Subscribers
+	}
+
+	rows := make([]types.Subscriber, 0, len(list))
+	for _, entry := range list {
+		row := make(types.Subscriber, len(entry))
+		for column, value := range entry {
+			column = normalizeDatasetColumn(column)
+			if snssai, ok := value.(map[string]interface{}); ok {
+				// S-NSSAI written as {sst, sd}
+				row["sst"], row["sd"] = scalarString(snssai["sst"]), scalarString(snssai["sd"])
+				continue
+			}
+			row[column] = scalarString(value)
+		}
+		rows = append(rows, completeSubscriber(row))
+	}
+	return rows, nil
+}
+
+// completeSubscriber derives sst and sd from an S-NSSAI written as SST-SD, and the other way round
+func completeSubscriber(row types.Subscriber) types.Subscriber {
+	if snssai := row["snssai"]; snssai != "" && row["sst"] == "" {
+		sst, sd, _ := strings.Cut(strings.ReplaceAll(snssai, ":", "-"), "-")
+		row["sst"], row["sd"] = sst, sd
+	} else if row["snssai"] == "" && row["sst"] != "" {
+		row["snssai"] = row["sst"]
+		if row["sd"] != "" {
+			row["snssai"] += "-" + row["sd"]
+		}
+	}
+	return row
+}
+
+// normalizeDatasetColumn lower-cases a column name and drops separators (S-NSSAI → snssai)
+func normalizeDatasetColumn(column string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(column)))
+}
+
+// draw returns the subscriber of the next request
+func (d *subscriberDataset) draw() types.Subscriber {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if row := d.peeked; row != nil {
+		d.peeked = nil
+		return row
+	}
+	return d.drawNext()
+}
+
+// peek returns the subscriber the next draw returns, without using it up
+func (d *subscriberDataset) peek() types.Subscriber {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.peeked == nil {
+		d.peeked = d.drawNext()
+	}
+	return d.peeked
+}
+
+// drawNext advances the configured order by one subscriber, the caller holds mu
+func (d *subscriberDataset) drawNext() types.Subscriber {
+	switch d.order {
+	case types.DatasetUniform:
+		return d.rows[d.rand.IntN(len(d.rows))]
+	case types.DatasetZipf:
+		return d.rows[d.zipf.Uint64()]
+	case types.DatasetRandom:
+		if d.next == len(d.rows) {
+			d.perm, d.next = d.rand.Perm(len(d.rows)), 0
+		}
+		d.next++
+		return d.rows[d.perm[d.next-1]]
+	}
+
+	row := d.rows[d.next%len(d.rows)]
+	d.next++
+	return row
+}
+
+// checkColumns reports columns that templates use but the dataset does not have
+func (d *subscriberDataset) checkColumns(columns []string) error {
+	for _, column := range columns {
+		if d == nil {
+			return fmt.Errorf("{{subscriber.%s}} needs global_settings.dataset_file", column)
+		}
+		if !containsString(d.columns, column) {
+			return fmt.Errorf("{{subscriber.%s}}: dataset %s has no column '%s' (columns: %s)", column, d.file, column, strings.Join(d.columns, ", "))
+		}
+	}
+	return nil
+}
+
+// String summarizes the dataset in one line
+func (d *subscriberDataset) String() string {
+	return fmt.Sprintf("%s (%d subscribers, %s order)", d.file, len(d.rows), d.order)
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// Modes of global_settings.discovery_cache
+const (
+	DiscoveryCacheMemory = "memory" // Results are reused within one run
+	DiscoveryCacheDisk   = "disk"   // Results are also kept in DiscoveryCachePath for later runs
+	DiscoveryCacheOff    = "off"    // Every API discovers again
+)
+
+// DiscoveryCachePath is where discovery results are kept between runs
+const DiscoveryCachePath = ".ctrlbench/discovery_cache.json"
+
+// discoveryCacheModes lists the modes of global_settings.discovery_cache
+var discoveryCacheModes = []string{DiscoveryCacheMemory, DiscoveryCacheDisk, DiscoveryCacheOff}
+
+// discoveryEntry is a search result of the NRF, valid until Expires
+type discoveryEntry struct {
+	Result  types.SearchResult `json:"result"`
+	Fetched time.Time          `json:"fetched"`
+	Expires time.Time          `json:"expires"`
+}
+
+// discoveryResultCache holds search results keyed by the full discovery query
+type discoveryResultCache struct {
+	mu         sync.Mutex
+	entries    map[string]discoveryEntry
+	diskLoaded bool
+}
+
+var (
+	discoveryCache   = &discoveryResultCache{entries: make(map[string]discoveryEntry)}
+	refreshDiscovery bool
+)
+
+// SetDiscoveryRefresh makes discovery query the NRF even when a cached result is valid.
+// The fresh result replaces the cached one.
+func SetDiscoveryRefresh(refresh bool) {
+	refreshDiscovery = refresh
+}
+
+// discoveryCacheMode reads global_settings.discovery_cache, memory when not set
+func discoveryCacheMode(globalSettings map[string]interface{}) (string, error) {
+	mode, _ := getCfgString(globalSettings["discovery_cache"])
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		return DiscoveryCacheMemory, nil
+	}
+	if !containsString(discoveryCacheModes, mode) {
+		return "", fmt.Errorf("global_settings.discovery_cache: unknown mode '%s' (%s)", mode, strings.Join(discoveryCacheModes, ", "))
+	}
+	return mode, nil
+}
+
+// lookup returns the cached result of a query and how long it stays valid
+func (c *discoveryResultCache) lookup(query, mode string) (*types.SearchResult, time.Duration, bool) {
+	if mode == DiscoveryCacheOff || refreshDiscovery {
+		return nil, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if mode == DiscoveryCacheDisk {
+		c.loadDisk()
+	}
+
+	entry, exists := c.entries[query]
+	if !exists {
+		return nil, 0, false
+	}
+	remaining := time.Until(entry.Expires)
+	if remaining <= 0 {
+		delete(c.entries, query)
+		return nil, 0, false
+	}
+	result := entry.Result
+	return &result, remaining, true
+}
+
+// store caches a result for its validityPeriod. Results without one are not cached.
+func (c *discoveryResultCache) store(query, mode string, result *types.SearchResult) bool {
+	if mode == DiscoveryCacheOff || result.ValidityPeriod <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[query] = discoveryEntry{
+		Result:  *result,
+		Fetched: now,
+		Expires: now.Add(time.Duration(result.ValidityPeriod) * time.Second),
+	}
+	if mode == DiscoveryCacheDisk {
+		c.loadDisk() // Keeps the results of other queries when the lookup was skipped
+		if err := c.writeDisk(); err != nil {
+			fmt.Printf("⚠️  Failed to write discovery cache %s: %v\n", DiscoveryCachePath, err)
+		}
+	}
+	return true
+}
+
+// loadDisk reads the cache file once
+func (c *discoveryResultCache) loadDisk() {
+	if c.diskLoaded {
+		return
+	}
+	c.diskLoaded = true
+	if err := c.readDisk(); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️  Ignoring discovery cache %s: %v\n", DiscoveryCachePath, err)
+	}
+}
+
+// readDisk merges the unexpired results of the cache file
+func (c *discoveryResultCache) readDisk() error {
+	data, err := os.ReadFile(DiscoveryCachePath)
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]discoveryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for query, entry := range entries {
+		if _, exists := c.entries[query]; !exists && time.Now().Before(entry.Expires) {
+			c.entries[query] = entry
+		}
+	}
+	return nil
+}
+
+// writeDisk stores the unexpired results atomically so a concurrent run never reads a partial file
+func (c *discoveryResultCache) writeDisk() error {
+	entries := make(map[string]discoveryEntry, len(c.entries))
+	for query, entry := range c.entries {
+		if time.Now().Before(entry.Expires) {
+			entries[query] = entry
+		}
+	}
+
+	// Queries are kept readable, & is not escaped
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(DiscoveryCachePath), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(DiscoveryCachePath), filepath.Base(DiscoveryCachePath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), DiscoveryCachePath)
+}
@@ -7,28 +7,54 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/devuk0204/ctrlbench/types"
-	"gopkg.in/yaml.v3"
 )
 
 // APIExecutor handles API execution and benchmarking
 type APIExecutor struct {
 	Timeout time.Duration
+
+	// Per NF, created from its nf_settings policy on first use
+	mu       sync.Mutex
+	clients  map[string]*http.Client
+	limiters map[string]*rateLimiter
+	tokens   map[string]accessToken
+
+	// Per API, serialized once with the template expressions of its values
+	requests map[*types.APIExecutionInfo]*preparedRequest
+
+	// Per API, spreads requests across the discovered NF instances
+	balancers map[*types.APIExecutionInfo]*instanceBalancer
+
+	// Results per NF instance ID
+	instanceStats map[string]*types.InstanceStats
+	instanceOrder []string
+
+	// Subscribers of global_settings.dataset_file, shared by every API of the run
+	dataset *subscriberDataset
 }
 
 // NewAPIExecutor creates a new API executor
 func NewAPIExecutor(timeout time.Duration) *APIExecutor {
 	return &APIExecutor{
-		Timeout: timeout,
+		Timeout:  timeout,
+		clients:  make(map[string]*http.Client),
+		limiters: make(map[string]*rateLimiter),
+		tokens:   make(map[string]accessToken),
+		requests: make(map[*types.APIExecutionInfo]*preparedRequest),
+
+		balancers:     make(map[*types.APIExecutionInfo]*instanceBalancer),
+		instanceStats: make(map[string]*types.InstanceStats),
 	}
 }
 
 // ExecuteAPI executes a specific API call using api_list.yaml
-func (e *APIExecutor) ExecuteAPI(targetNF, apiName string) (*types.APIExecutionInfo, error) {
+// serviceName may be empty unless the API name is ambiguous within the NF.
+func (e *APIExecutor) ExecuteAPI(targetNF, serviceName, apiName string) (*types.APIExecutionInfo, error) {
 	// Load API list
 	apiList, err := LoadAPIList()
 	if err != nil {
@@ -36,25 +62,48 @@ func (e *APIExecutor) ExecuteAPI(targetNF, apiName string) (*types.APIExecutionI
 	}
 
 	// Load configuration
-	config, err := e.loadConfiguration()
+	config, err := LoadEffectiveConfiguration()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Disabled NFs are refused before anything is prepared
+	policy, err := ResolveNFPolicy(config, targetNF)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NF policy: %w", err)
+	}
+	if !policy.Enabled {
+		return nil, fmt.Errorf("%s is disabled in nf_settings.%s.enabled", targetNF, targetNF)
+	}
+	fmt.Printf("📋 %s policy: %s\n", targetNF, DescribeNFPolicy(policy))
+
 	// Prepare execution info from api_list and configuration (with required validation)
-	execInfo, err := PrepareAPIExecution(apiList, config, targetNF, apiName)
+	execInfo, err := PrepareAPIExecution(apiList, config, targetNF, serviceName, apiName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare API execution: %w", err)
 	}
+	execInfo.Policy = policy
+	execInfo.Scopes = SelectScopes(execInfo.ScopeSets, policy.Auth.Scopes)
 
 	// Get global settings
-	userInputs := config["user_inputs"].(map[string]interface{})
-	globalSettings := userInputs["global_settings"].(map[string]interface{})
+	globalSettings := config.UserInputs.GlobalSettings
+
+	if e.dataset == nil {
+		if e.dataset, err = LoadSubscriberDataset(globalSettings); err != nil {
+			return nil, fmt.Errorf("failed to load subscriber dataset: %w", err)
+		}
+		if e.dataset != nil {
+			fmt.Printf("📄 Subscriber dataset: %s\n", e.dataset)
+		}
+	}
 
 	var discoveredURL string
 
-	// Skip NF Discovery for NRF - use NRF URL directly
-	if strings.ToUpper(targetNF) == "NRF" {
+	// base_url of the NF skips discovery, NRF is reached directly
+	if policy.BaseURL != "" {
+		discoveredURL = policy.BaseURL
+		fmt.Printf("✅ Using %s base_url from nf_settings: %s\n", targetNF, discoveredURL)
+	} else if strings.ToUpper(targetNF) == "NRF" {
 		nrfURL, ok := getCfgString(globalSettings["nrf_url"])
 		if !ok || nrfURL == "" {
 			return nil, fmt.Errorf("NRF URL is required in configuration for NRF target")
@@ -62,121 +111,135 @@ func (e *APIExecutor) ExecuteAPI(targetNF, apiName string) (*types.APIExecutionI
 		discoveredURL = nrfURL
 		fmt.Printf("✅ Using direct NRF URL: %s\n", discoveredURL)
 	} else {
-		// Discover NF URL for other NFs
-		var err error
-		discoveredURL, err = e.discoverNFURL(globalSettings, targetNF)
+		// Discover NF URL for other NFs, including the apiPrefix of the target service
+		serviceInfo := apiList[targetNF][execInfo.Service]
+
+		query, err := ResolveDiscoveryQuery(config, targetNF)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discovery query: %w", err)
+		}
+		if len(query) > 0 {
+			readable, _ := url.QueryUnescape(query.Encode())
+			fmt.Printf("🔎 Discovery query: %s\n", readable)
+		}
+		instances, err := e.discoverNFInstances(globalSettings, targetNF, serviceInfo.APIName, query)
 		if err != nil {
 			return nil, fmt.Errorf("NF discovery failed: %w", err)
 		}
 
 		// For testing purposes, replace discovered URL
-		if discoveredURL == "http://controlplane-free5gc-ausf-service:80" {
-			discoveredURL = "http://10.96.43.148:80"
+		for i := range instances {
+			if instances[i].URL == "http://controlplane-free5gc-ausf-service:80" {
+				instances[i].URL = "http://10.96.43.148:80"
+			}
 		}
 
-		fmt.Printf("✅ Discovered %s URL: %s\n", targetNF, discoveredURL)
+		discoveredURL = instances[0].URL
+		execInfo.Instances = instances
+		if len(instances) == 1 {
+			fmt.Printf("✅ Discovered %s URL: %s\n", targetNF, discoveredURL)
+		} else {
+			fmt.Printf("✅ Discovered %d %s instances, requests are spread %s:\n", len(instances), targetNF, policy.LoadBalance)
+			for _, inst := range instances {
+				fmt.Printf("   %s %s (priority %d, capacity %d, load %d%%)\n", inst.InstanceID, inst.URL, inst.Priority, inst.Capacity, inst.Load)
+			}
+		}
 	}
 
 	execInfo.DiscoveredURL = discoveredURL
 
 	// Populate headers
 	e.populateHeaders(execInfo, targetNF, config)
+	if err := e.applyParameterHeaders(execInfo); err != nil {
+		return nil, err
+	}
 
-	// Build and display final URL once
-	finalURL := e.buildFinalURL(execInfo)
-	fmt.Printf("🔗 Final URL: %s\n", finalURL)
+	// Build and display final URL once, template expressions are evaluated per request
+	request, err := e.request(execInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
+	fmt.Printf("🔗 Final URL: %s\n", request.url)
+	if request.expressions > 0 {
+		fmt.Printf("🔄 %d templated values evaluated per request\n", request.expressions)
+	}
 
 	return execInfo, nil
 }
 
 // buildFinalURL constructs the complete URL for the API call
-func (e *APIExecutor) buildFinalURL(execInfo *types.APIExecutionInfo) string {
+func (e *APIExecutor) buildFinalURL(execInfo *types.APIExecutionInfo) (string, error) {
+	serialized, err := SerializeParameters(execInfo.Parameters)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize parameters: %w", err)
+	}
+
 	// Replace path parameters in the URL
 	finalPath := execInfo.Path
-	queryParams := make(map[string]string)
-
-	// Separate path and query parameters
-	for paramName, paramValue := range execInfo.Parameters {
-		if paramValue == "" {
-			continue // Skip empty parameters
-		}
-
-		// Check if this is a path parameter
-		placeholder := fmt.Sprintf("{%s}", paramName)
-		if strings.Contains(finalPath, placeholder) {
-			// Path parameter - replace in URL path
-			finalPath = strings.ReplaceAll(finalPath, placeholder, paramValue)
-		} else {
-			// Query parameter - add to query string
-			queryParams[paramName] = paramValue
-		}
+	for paramName, paramValue := range serialized.PathValues {
+		finalPath = strings.ReplaceAll(finalPath, fmt.Sprintf("{%s}", paramName), paramValue)
 	}
 
-	// Get service path from api_list.yaml
-	servicePath := e.getServicePath(execInfo.NF, execInfo.APIName)
-
-	// Build base URL: NF Discovery URL + Service Path + API Path
+	// Build base URL: NF Discovery URL (with apiPrefix) + Service Path + API Path
 	baseURL := strings.TrimSuffix(execInfo.DiscoveredURL, "/")
 	apiPath := strings.TrimPrefix(finalPath, "/")
-	fullURL := fmt.Sprintf("%s%s/%s", baseURL, servicePath, apiPath)
+	fullURL := fmt.Sprintf("%s%s/%s", baseURL, execInfo.ServicePath, apiPath)
 
 	// Add query parameters if any
-	if len(queryParams) > 0 {
-		queryValues := url.Values{}
-		for key, value := range queryParams {
-			queryValues.Set(key, value)
-		}
-		fullURL += "?" + queryValues.Encode()
+	if serialized.Query != "" {
+		fullURL += "?" + serialized.Query
 	}
 
-	return fullURL
+	return fullURL, nil
 }
 
-// discoverNFURL discovers NF URL using NRF
-func (e *APIExecutor) discoverNFURL(globalCfg map[string]interface{}, targetNF string) (string, error) {
-	return NFDiscoveryURL(globalCfg, targetNF)
-}
-
-// loadConfiguration loads configuration.yaml
-func (e *APIExecutor) loadConfiguration() (map[string]interface{}, error) {
-	data, err := os.ReadFile("configuration.yaml")
+// applyParameterHeaders sets header and cookie parameters as request headers
+func (e *APIExecutor) applyParameterHeaders(execInfo *types.APIExecutionInfo) error {
+	serialized, err := SerializeParameters(execInfo.Parameters)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read configuration.yaml: %w", err)
+		return fmt.Errorf("failed to serialize parameters: %w", err)
 	}
 
-	var config map[string]interface{}
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse configuration.yaml: %w", err)
+	for key, value := range serialized.Headers {
+		fmt.Printf("🔍 DEBUG: Setting parameter header %s: %s\n", key, value)
+		execInfo.Headers[key] = value
 	}
+	return nil
+}
 
-	return config, nil
+// discoverNFInstances discovers the NF instances using NRF
+func (e *APIExecutor) discoverNFInstances(globalCfg map[string]interface{}, targetNF, serviceName string, query url.Values) ([]types.NFEndpoint, error) {
+	return NFDiscoveryInstances(globalCfg, targetNF, serviceName, query)
 }
 
 // populateHeaders populates HTTP headers for the request
-func (e *APIExecutor) populateHeaders(execInfo *types.APIExecutionInfo, targetNF string, config map[string]interface{}) {
-	// Set default headers
-	execInfo.Headers["Content-Type"] = "application/json"
+func (e *APIExecutor) populateHeaders(execInfo *types.APIExecutionInfo, targetNF string, config *types.ConfigurationFile) {
+	// Set default headers, the body is sent as the media type the operation declares
+	execInfo.Headers["Content-Type"] = types.ContentTypeJSON
+	if execInfo.ContentType != "" {
+		execInfo.Headers["Content-Type"] = execInfo.ContentType
+	}
 	execInfo.Headers["Accept"] = "application/json"
+	defer func() {
+		// A Content-Type custom header applies to JSON bodies only
+		if execInfo.ContentType != "" && execInfo.ContentType != types.ContentTypeJSON {
+			execInfo.Headers["Content-Type"] = execInfo.ContentType
+		}
+	}()
 
 	// Add NF-specific headers from configuration
-	userInputs, ok := config["user_inputs"].(map[string]interface{})
-	if !ok {
-		fmt.Printf("🔍 DEBUG: No user_inputs found in configuration\n")
-		return
-	}
-
-	nfSettings, ok := userInputs["nf_settings"].(map[string]interface{})
-	if !ok {
+	nfSettings := config.UserInputs.NFSettings
+	if nfSettings == nil {
 		fmt.Printf("🔍 DEBUG: No nf_settings found in configuration\n")
 		return
 	}
 
 	fmt.Printf("🔍 DEBUG: Looking for NF settings for: %s\n", targetNF)
 
-	if nfConfig, exists := nfSettings[targetNF]; exists {
+	if nfMap, exists := nfSettings[targetNF]; exists {
 		fmt.Printf("🔍 DEBUG: Found NF config for %s\n", targetNF)
 
-		if nfMap, ok := nfConfig.(map[string]interface{}); ok {
+		if nfMap != nil {
 			if customHeaders, exists := nfMap["custom_headers"]; exists {
 				fmt.Printf("🔍 DEBUG: Found custom_headers section\n")
 
@@ -208,7 +271,7 @@ func (e *APIExecutor) populateHeaders(execInfo *types.APIExecutionInfo, targetNF
 				fmt.Printf("🔍 DEBUG: No custom_headers found for %s\n", targetNF)
 			}
 		} else {
-			fmt.Printf("🔍 DEBUG: NF config is not a map\n")
+			fmt.Printf("🔍 DEBUG: NF config is empty\n")
 		}
 	} else {
 		fmt.Printf("🔍 DEBUG: No configuration found for NF: %s\n", targetNF)
@@ -217,38 +280,56 @@ func (e *APIExecutor) populateHeaders(execInfo *types.APIExecutionInfo, targetNF
 	fmt.Printf("🔍 DEBUG: Final headers: %v\n", execInfo.Headers)
 }
 
-// ExecuteHTTPCall performs the actual HTTP call
-func (e *APIExecutor) ExecuteHTTPCall(execInfo *types.APIExecutionInfo) (time.Duration, error) {
-	start := time.Now()
+// ExecuteHTTPCall performs the actual HTTP call with the NF's policy. With several
+// discovered instances, each call goes to the one picked by the NF's load_balancing.
+func (e *APIExecutor) ExecuteHTTPCall(execInfo *types.APIExecutionInfo) (duration time.Duration, err error) {
+	client, limiter, err := e.policyClient(execInfo.Policy)
+	if err != nil {
+		return 0, err
+	}
+	authorization, err := e.authorization(client, execInfo)
+	if err != nil {
+		return 0, err
+	}
 
-	// Build full URL using the same logic as buildFinalURL
-	fullURL := e.buildFinalURL(execInfo)
+	// Templated values are evaluated before the clock starts
+	request, err := e.request(execInfo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare request: %w", err)
+	}
+	rendered := request.render(0) // Requests are sent one after another, by worker 0
+	fullURL := rendered.url
+	if balancer := e.balancer(execInfo); balancer != nil {
+		inst := balancer.pick()
+		fullURL = instanceURL(execInfo, inst, fullURL)
+		defer func() { e.recordInstance(inst, duration, err) }()
+	}
 
-	// Prepare request body
-	var requestBody []byte
-	if execInfo.RequestBody != nil {
-		var err error
-		requestBody, err = json.Marshal(execInfo.RequestBody)
-		if err != nil {
-			return 0, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		fmt.Printf("🔍 DEBUG: Request Body: %s\n", string(requestBody))
+	if rendered.body != nil {
+		fmt.Printf("🔍 DEBUG: Request Body: %s\n", string(rendered.body))
 	} else {
 		fmt.Printf("🔍 DEBUG: No request body\n")
 	}
 
+	// Waiting for the rate cap is not part of the response time
+	limiter.Wait()
+	start := time.Now()
+
 	// Create HTTP request
-	req, err := http.NewRequest(execInfo.Method, fullURL, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequest(execInfo.Method, fullURL, bytes.NewReader(rendered.body))
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers from execInfo
 	fmt.Printf("🔍 DEBUG: Adding headers to request:\n")
-	for key, value := range execInfo.Headers {
+	for key, value := range rendered.headers {
 		req.Header.Set(key, value)
 		fmt.Printf("   %s: %s\n", key, value)
 	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
 
 	// Debug: Print all request headers (including any defaults added by Go)
 	fmt.Printf("🔍 DEBUG: Final request headers:\n")
@@ -259,9 +340,11 @@ func (e *APIExecutor) ExecuteHTTPCall(execInfo *types.APIExecutionInfo) (time.Du
 	}
 
 	fmt.Printf("🔍 DEBUG: Making %s request to: %s\n", execInfo.Method, fullURL)
+	if execInfo.Policy.HTTPVersion != types.HTTPVersionDefault {
+		fmt.Printf("🔍 DEBUG: HTTP version: %s\n", execInfo.Policy.HTTPVersion)
+	}
 
 	// Execute request
-	client := &http.Client{Timeout: e.Timeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		fmt.Printf("🔍 DEBUG: Request failed with error: %v\n", err)
@@ -269,7 +352,7 @@ func (e *APIExecutor) ExecuteHTTPCall(execInfo *types.APIExecutionInfo) (time.Du
 	}
 	defer resp.Body.Close()
 
-	duration := time.Since(start)
+	duration = time.Since(start)
 
 	// Debug: Print response status and headers
 	fmt.Printf("🔍 DEBUG: Response Status: %s (%d)\n", resp.Status, resp.StatusCode)
@@ -314,25 +397,61 @@ func (e *APIExecutor) ExecuteHTTPCall(execInfo *types.APIExecutionInfo) (time.Du
 	return duration, nil
 }
 
-// getServicePath retrieves service path from api_list.yaml
-func (e *APIExecutor) getServicePath(nf, apiName string) string {
-	apiList, err := LoadAPIList()
+// policyClient returns the HTTP client and rate limiter of an NF policy
+func (e *APIExecutor) policyClient(policy types.NFPolicy) (*http.Client, *rateLimiter, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if client, exists := e.clients[policy.NF]; exists {
+		return client, e.limiters[policy.NF], nil
+	}
+
+	client, err := newPolicyHTTPClient(policy, e.Timeout)
 	if err != nil {
-		fmt.Printf("⚠️  Failed to load API list: %v\n", err)
-		return ""
+		return nil, nil, fmt.Errorf("invalid %s TLS settings: %w", policy.NF, err)
 	}
+	e.clients[policy.NF] = client
+	e.limiters[policy.NF] = newRateLimiter(policy.RateLimit)
+	return client, e.limiters[policy.NF], nil
+}
 
-	if nfServices, exists := apiList[nf]; exists {
-		for _, serviceInfo := range nfServices {
-			if _, exists := serviceInfo.APIs[apiName]; exists {
-				return serviceInfo.Path
-			}
+// authorization returns the Authorization header of the NF's auth mode. OAuth2 tokens
+// are requested from the NRF once per scope set and renewed when they expire.
+func (e *APIExecutor) authorization(client *http.Client, execInfo *types.APIExecutionInfo) (string, error) {
+	policy := execInfo.Policy
+	switch policy.Auth.Mode {
+	case types.AuthModeBearer:
+		return "Bearer " + policy.Auth.Token, nil
+	case types.AuthModeOAuth2:
+	default:
+		return "", nil
+	}
+
+	key := policy.NF + " " + strings.Join(execInfo.Scopes, " ")
+	e.mu.Lock()
+	token, cached := e.tokens[key]
+	e.mu.Unlock()
+	if cached && (token.expires.IsZero() || time.Now().Before(token.expires)) {
+		return "Bearer " + token.value, nil
+	}
+
+	if policy.Auth.NRF != nil {
+		nrfClient, _, err := e.policyClient(*policy.Auth.NRF)
+		if err != nil {
+			return "", err
 		}
+		client = nrfClient
 	}
 
-	// Fallback: generate default service path
-	nfLower := strings.ToLower(nf)
-	return fmt.Sprintf("/n%s-auth/v1", nfLower)
+	fmt.Printf("🔍 DEBUG: Requesting OAuth2 access token for scope '%s'\n", strings.Join(execInfo.Scopes, " "))
+	token, err := requestAccessToken(client, policy, execInfo.Scopes)
+	if err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	e.tokens[key] = token
+	e.mu.Unlock()
+	return "Bearer " + token.value, nil
 }
 
 // RunBenchmark runs benchmark for specified iterations
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SetJSONPath sets value at jsonPath inside doc and returns the updated document.
+// jsonPath is either a JSON pointer (/nfServices/0/versions) or a dotted path
+// with optional "$." prefix and [n] indexes ($.nfServices[0].versions).
+// Missing objects on the way are created, "-" or an index equal to the length appends.
+func SetJSONPath(doc interface{}, jsonPath string, value interface{}) (interface{}, error) {
+	tokens, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return doc, err
+	}
+	return setPathTokens(doc, tokens, value, jsonPath)
+}
+
+// ApplyJSONPathOverrides applies path → value overrides to doc in sorted path order,
+// so parent paths are set before the nested paths that refine them
+func ApplyJSONPathOverrides(doc interface{}, overrides map[string]interface{}) (interface{}, error) {
+	paths := make([]string, 0, len(overrides))
+	for jsonPath := range overrides {
+		paths = append(paths, jsonPath)
+	}
+	sort.Strings(paths)
+
+	for _, jsonPath := range paths {
+		var err error
+		if doc, err = SetJSONPath(doc, jsonPath, normalizeJSONValue(overrides[jsonPath])); err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// parseJSONPath splits a JSON pointer or dotted path into tokens
+func parseJSONPath(jsonPath string) ([]string, error) {
+	if jsonPath == "" || jsonPath == "$" || jsonPath == "/" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(jsonPath, "/") {
+		var tokens []string
+		for _, token := range strings.Split(jsonPath[1:], "/") {
+			tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+		return tokens, nil
+	}
+
+	var tokens []string
+	for _, part := range strings.Split(strings.TrimPrefix(jsonPath, "$."), ".") {
+		name, rest, hasIndex := strings.Cut(part, "[")
+		if name != "" {
+			tokens = append(tokens, name)
+		} else if !hasIndex {
+			return nil, fmt.Errorf("invalid JSON path '%s': empty segment", jsonPath)
+		}
+
+		for hasIndex {
+			index, remainder, closed := strings.Cut(rest, "]")
+			if !closed || index == "" {
+				return nil, fmt.Errorf("invalid JSON path '%s': unterminated index", jsonPath)
+			}
+			tokens = append(tokens, strings.Trim(index, `'"`))
+
+			if remainder == "" {
+				break
+			}
+			if !strings.HasPrefix(remainder, "[") {
+				return nil, fmt.Errorf("invalid JSON path '%s': unexpected '%s'", jsonPath, remainder)
+			}
+			rest = remainder[1:]
+		}
+	}
+	return tokens, nil
+}
+
+// setPathTokens sets value below node following tokens
+func setPathTokens(node interface{}, tokens []string, value interface{}, jsonPath string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token := tokens[0]
+
+	switch current := node.(type) {
+	case []interface{}:
+		index := len(current)
+		if token != "-" {
+			var err error
+			if index, err = strconv.Atoi(token); err != nil || index < 0 || index > len(current) {
+				return node, fmt.Errorf("JSON path '%s': index '%s' out of range (array has %d items)", jsonPath, token, len(current))
+			}
+		}
+
+		var child interface{}
+		if index < len(current) {
+			child = current[index]
+		}
+		updated, err := setPathTokens(child, tokens[1:], value, jsonPath)
+		if err != nil {
+			return node, err
+		}
+		if index == len(current) {
+			return append(current, updated), nil
+		}
+		current[index] = updated
+		return current, nil
+
+	case map[string]interface{}:
+		updated, err := setPathTokens(current[token], tokens[1:], value, jsonPath)
+		if err != nil {
+			return node, err
+		}
+		current[token] = updated
+		return current, nil
+
+	case nil:
+		// Create the missing container, an array when the path continues with an index
+		if token == "-" || token == "0" {
+			return setPathTokens([]interface{}{}, tokens, value, jsonPath)
+		}
+		return setPathTokens(map[string]interface{}{}, tokens, value, jsonPath)
+
+	default:
+		return node, fmt.Errorf("JSON path '%s': cannot set '%s' inside a %T value", jsonPath, token, node)
+	}
+}
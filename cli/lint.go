@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// PrintLintReport prints a lint report in text or json format
+func PrintLintReport(report *types.LintReport, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "text", "":
+		printLintText(report)
+		return nil
+	default:
+		return fmt.Errorf("unknown lint output format '%s' (text, json)", format)
+	}
+}
+
+// printLintText prints lint issues grouped by file
+func printLintText(report *types.LintReport) {
+	fmt.Println("🔎 OpenAPI Lint Report")
+	fmt.Println(strings.Repeat("=", 50))
+
+	for _, file := range report.Files {
+		if len(file.Issues) == 0 {
+			fmt.Printf("✅ %s\n", file.File)
+			continue
+		}
+
+		fmt.Printf("📄 %s (NF: %s, service: %s)\n", file.File, file.NF, file.Service)
+		for _, issue := range file.Issues {
+			icon := "⚠️ "
+			if issue.Severity == types.LintError {
+				icon = "❌"
+			}
+
+			position := ""
+			if issue.Line > 0 {
+				position = fmt.Sprintf("line %d ", issue.Line)
+			}
+			fmt.Printf("    %s %s[%s] %s\n", icon, position, issue.Rule, issue.Message)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Printf("Files: %d, Errors: %d, Warnings: %d\n", len(report.Files), report.Errors, report.Warnings)
+}
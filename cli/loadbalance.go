@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// instanceBalancer spreads the requests of an API across the discovered NF instances
+type instanceBalancer struct {
+	mode      string
+	instances []types.NFEndpoint
+
+	mu      sync.Mutex
+	next    int
+	weights []int // Capacity per instance, weighted mode
+	current []int // Smooth weighted round-robin state
+}
+
+// newInstanceBalancer creates the balancer of an NF policy's load_balancing mode
+func newInstanceBalancer(mode string, instances []types.NFEndpoint) *instanceBalancer {
+	b := &instanceBalancer{mode: mode, instances: instances}
+	if mode != types.LoadBalanceWeighted {
+		return b
+	}
+
+	// Only the best (lowest) priority receives requests, the others are standby
+	best := instances[0].Priority
+	for _, inst := range instances {
+		best = min(best, inst.Priority)
+	}
+	b.weights = make([]int, len(instances))
+	for i, inst := range instances {
+		switch {
+		case inst.Priority != best:
+			b.weights[i] = 0
+		case inst.Capacity > 0:
+			b.weights[i] = inst.Capacity
+		default:
+			b.weights[i] = 1 // Instances without capacity share equally
+		}
+	}
+	b.current = make([]int, len(instances))
+	return b
+}
+
+// pick returns the instance of the next request
+func (b *instanceBalancer) pick() types.NFEndpoint {
+	switch b.mode {
+	case types.LoadBalanceFirst:
+		return b.instances[0]
+	case types.LoadBalanceRandom:
+		return b.instances[rand.IntN(len(b.instances))]
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.mode == types.LoadBalanceWeighted {
+		// Smooth weighted round-robin, an instance of weight w gets w requests per round
+		// without bursts to the heaviest one
+		total, chosen := 0, 0
+		for i, weight := range b.weights {
+			b.current[i] += weight
+			total += weight
+			if b.current[i] > b.current[chosen] {
+				chosen = i
+			}
+		}
+		b.current[chosen] -= total
+		return b.instances[chosen]
+	}
+
+	inst := b.instances[b.next]
+	b.next = (b.next + 1) % len(b.instances)
+	return inst
+}
+
+// balancer returns the balancer of an API, creating it on first use.
+// APIs without discovered instances have none.
+func (e *APIExecutor) balancer(execInfo *types.APIExecutionInfo) *instanceBalancer {
+	if len(execInfo.Instances) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if b, exists := e.balancers[execInfo]; exists {
+		return b
+	}
+	b := newInstanceBalancer(execInfo.Policy.LoadBalance, execInfo.Instances)
+	e.balancers[execInfo] = b
+	return b
+}
+
+// instanceURL points a request URL, built with the discovered URL, to another instance
+func instanceURL(execInfo *types.APIExecutionInfo, inst types.NFEndpoint, fullURL string) string {
+	base := strings.TrimSuffix(execInfo.DiscoveredURL, "/")
+	if inst.URL == execInfo.DiscoveredURL || !strings.HasPrefix(fullURL, base) {
+		return fullURL
+	}
+	return strings.TrimSuffix(inst.URL, "/") + strings.TrimPrefix(fullURL, base)
+}
+
+// recordInstance adds a request to the results of the instance it was sent to
+func (e *APIExecutor) recordInstance(inst types.NFEndpoint, duration time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats, exists := e.instanceStats[inst.InstanceID]
+	if !exists {
+		stats = &types.InstanceStats{InstanceID: inst.InstanceID, URL: inst.URL, MinTime: duration}
+		e.instanceStats[inst.InstanceID] = stats
+		e.instanceOrder = append(e.instanceOrder, inst.InstanceID)
+	}
+
+	stats.Requests++
+	stats.TotalTime += duration
+	stats.MinTime = min(stats.MinTime, duration)
+	stats.MaxTime = max(stats.MaxTime, duration)
+	if err != nil {
+		stats.FailureCount++
+		stats.LastError = err.Error()
+	} else {
+		stats.SuccessCount++
+	}
+}
+
+// InstanceStats returns the results per NF instance, in the order instances were first used
+func (e *APIExecutor) InstanceStats() []types.InstanceStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := make([]types.InstanceStats, 0, len(e.instanceOrder))
+	for _, id := range e.instanceOrder {
+		stats = append(stats, *e.instanceStats[id])
+	}
+	return stats
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,9 +30,121 @@ func trimSlashRight(s string) string {
 	return strings.TrimSuffix(s, "/")
 }
 
+// discoveryQueryParameters are the TS 29.510 query parameters of nf_settings.<NF>.discovery_query
+var discoveryQueryParameters = []string{
+	"service-names", "snssais", "dnn", "target-plmn-list", "preferred-locality",
+	"supi", "routing-indicator", "target-nf-instance-id", "requester-features",
+}
+
+// ResolveDiscoveryQuery encodes the discovery query parameters configured for an NF
+// in nf_settings.<NF>.discovery_query
+func ResolveDiscoveryQuery(config *types.ConfigurationFile, nf string) (url.Values, error) {
+	settings := lookupNFSettings(config.UserInputs.NFSettings, nf)
+	block, _ := settings["discovery_query"].(map[string]interface{})
+
+	query := url.Values{}
+	for _, name := range discoveryQueryParameters {
+		value := cfgValue(block[name])
+		if IsEmptyValue(value) {
+			continue
+		}
+		path := fmt.Sprintf("nf_settings.%s.discovery_query.%s", nf, name)
+		if text, ok := value.(string); ok && hasTemplate(text) {
+			return nil, fmt.Errorf("%s: templates are not evaluated in discovery queries", path)
+		}
+
+		var encoded string
+		var err error
+		switch name {
+		case "service-names":
+			var names []string
+			for _, item := range discoveryList(value) {
+				names = append(names, cfgText(item))
+			}
+			encoded = strings.Join(names, ",") // style=form, explode=false
+		case "snssais":
+			encoded, err = encodeDiscoverySnssais(discoveryList(value))
+		case "target-plmn-list":
+			encoded, err = encodeDiscoveryPlmns(discoveryList(value))
+		default:
+			encoded = cfgText(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		query.Set(name, encoded)
+	}
+	return query, nil
+}
+
+// discoveryList reads a list value, or a comma-separated string, as its items
+func discoveryList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case string:
+		var items []interface{}
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return []interface{}{value}
+}
+
+// encodeDiscoverySnssais encodes S-NSSAIs as the JSON array of the snssais parameter.
+// Items are {sst, sd} mappings or SST-SD strings.
+func encodeDiscoverySnssais(items []interface{}) (string, error) {
+	snssais := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		var sst, sd string
+		switch v := item.(type) {
+		case map[string]interface{}:
+			sst, sd = cfgText(v["sst"]), cfgText(v["sd"])
+		default:
+			sst, sd, _ = strings.Cut(strings.ReplaceAll(cfgText(v), ":", "-"), "-")
+		}
+		sstValue, err := strconv.Atoi(sst)
+		if err != nil || sstValue < 0 || sstValue > 255 {
+			return "", fmt.Errorf("invalid sst '%s' (0-255)", sst)
+		}
+		snssai := map[string]interface{}{"sst": sstValue}
+		if sd != "" {
+			snssai["sd"] = strings.ToUpper(sd)
+		}
+		snssais = append(snssais, snssai)
+	}
+	data, err := json.Marshal(snssais)
+	return string(data), err
+}
+
+// encodeDiscoveryPlmns encodes PLMN IDs as the JSON array of the target-plmn-list parameter.
+// Items are {mcc, mnc} mappings or MCC-MNC strings.
+func encodeDiscoveryPlmns(items []interface{}) (string, error) {
+	plmns := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		var mcc, mnc string
+		switch v := item.(type) {
+		case map[string]interface{}:
+			mcc, mnc = cfgText(v["mcc"]), cfgText(v["mnc"])
+		default:
+			mcc, mnc, _ = strings.Cut(cfgText(v), "-")
+		}
+		if len(mcc) != 3 || (len(mnc) != 2 && len(mnc) != 3) {
+			return "", fmt.Errorf("invalid PLMN '%s-%s' (3 digit MCC, 2 or 3 digit MNC)", mcc, mnc)
+		}
+		plmns = append(plmns, map[string]string{"mcc": mcc, "mnc": mnc})
+	}
+	data, err := json.Marshal(plmns)
+	return string(data), err
+}
+
 type NFDiscoveryClient struct {
 	NRFURL     string
 	HTTPClient *http.Client
+	CacheMode  string // global_settings.discovery_cache, empty disables the cache
 }
 
 func NewNFDiscoveryClient(nrfURL string, timeout time.Duration) *NFDiscoveryClient {
@@ -43,13 +156,17 @@ func NewNFDiscoveryClient(nrfURL string, timeout time.Duration) *NFDiscoveryClie
 	}
 }
 
+// DiscoverNF searches the NRF for instances of targetNFType matching the additional query parameters
 func (c *NFDiscoveryClient) DiscoverNF(
-	targetNFType, requesterNFType, requesterNFInstanceID string,
+	targetNFType, requesterNFType, requesterNFInstanceID string, query url.Values,
 ) (*types.SearchResult, error) {
 
 	base := fmt.Sprintf("%s/nnrf-disc/v1/nf-instances", c.NRFURL)
 
 	q := url.Values{}
+	for name, values := range query {
+		q[name] = values
+	}
 	q.Set("target-nf-type", strings.ToUpper(targetNFType))
 	if requesterNFType != "" {
 		q.Set("requester-nf-type", requesterNFType)
@@ -59,7 +176,12 @@ func (c *NFDiscoveryClient) DiscoverNF(
 	}
 
 	fullURL := base + "?" + q.Encode()
+	if cached, remaining, ok := discoveryCache.lookup(fullURL, c.cacheMode()); ok {
+		fmt.Printf("🔄 Discovery cache hit: %s (valid for %s more)\n", fullURL, remaining.Round(time.Second))
+		return cached, nil
+	}
 	fmt.Printf("🔍 NRF discovery URL : %s\n", fullURL)
+	start := time.Now()
 
 	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
 	if err != nil {
@@ -87,10 +209,30 @@ func (c *NFDiscoveryClient) DiscoverNF(
 	if err = json.Unmarshal(b, &res); err != nil {
 		return nil, fmt.Errorf("parse discovery response: %w", err)
 	}
+	fmt.Printf("🔍 NRF discovery took %v\n", time.Since(start))
+
+	switch {
+	case discoveryCache.store(fullURL, c.cacheMode(), &res):
+		fmt.Printf("📋 Discovery result cached for its validityPeriod of %ds\n", res.ValidityPeriod)
+	case c.cacheMode() != DiscoveryCacheOff:
+		fmt.Printf("⚠️  Discovery result has no validityPeriod, not cached\n")
+	}
 	return &res, nil
 }
 
-func (c *NFDiscoveryClient) nfURLfromProfile(p types.NFProfile) (string, bool) {
+// nfURLfromProfile builds the NF base URL from a profile. When serviceName is set,
+// the endpoints and apiPrefix of the matching NF service are preferred.
+func (c *NFDiscoveryClient) nfURLfromProfile(p types.NFProfile, serviceName string) (string, bool) {
+	if serviceName != "" {
+		for _, svc := range p.NFServices {
+			if svc.ServiceName != serviceName {
+				continue
+			}
+			if url, ok := serviceURL(svc); ok {
+				return url + trimSlashRight(svc.APIPrefix), true
+			}
+		}
+	}
 	for _, svc := range p.NFServices {
 		for _, ep := range svc.IpEndPoints {
 			switch {
@@ -110,52 +252,137 @@ func (c *NFDiscoveryClient) nfURLfromProfile(p types.NFProfile) (string, bool) {
 	return "", false
 }
 
-func (c *NFDiscoveryClient) DiscoverAndGetURL(
-	targetNFType, requesterNFType, requesterNFInstanceID string,
-) (string, error) {
+// serviceURL builds scheme://authority for a single NF service instance.
+func serviceURL(svc types.NFService) (string, bool) {
+	scheme := svc.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	for _, ep := range svc.IpEndPoints {
+		port := ep.Port
+		if port == 0 {
+			port = defaultPort(scheme)
+		}
+		switch {
+		case ep.IPv4Address != "":
+			return fmt.Sprintf("%s://%s:%d", scheme, ep.IPv4Address, port), true
+		case ep.IPv6Address != "":
+			return fmt.Sprintf("%s://[%s]:%d", scheme, ep.IPv6Address, port), true
+		}
+	}
+	if svc.FQDN != "" {
+		return fmt.Sprintf("%s://%s:%d", scheme, svc.FQDN, defaultPort(scheme)), true
+	}
+	return "", false
+}
+
+// cacheMode returns the discovery cache mode of the client
+func (c *NFDiscoveryClient) cacheMode() string {
+	if c.CacheMode == "" {
+		return DiscoveryCacheOff
+	}
+	return c.CacheMode
+}
+
+// defaultPort returns the default port of a URI scheme.
+func defaultPort(scheme string) int {
+	if scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+// DiscoverInstances discovers the instances of targetNFType requests can be sent to.
+// REGISTERED instances are preferred, the others are used only when none is registered.
+func (c *NFDiscoveryClient) DiscoverInstances(
+	targetNFType, serviceName, requesterNFType, requesterNFInstanceID string, query url.Values,
+) ([]types.NFEndpoint, error) {
 
-	res, err := c.DiscoverNF(targetNFType, requesterNFType, requesterNFInstanceID)
+	res, err := c.DiscoverNF(targetNFType, requesterNFType, requesterNFInstanceID, query)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(res.NFInstances) == 0 {
-		return "", fmt.Errorf("no %s instances found", targetNFType)
+		return nil, fmt.Errorf("no %s instances found", targetNFType)
 	}
 
-	// prefer REGISTERED → otherwise first instance
+	var registered, others []types.NFEndpoint
 	for _, inst := range res.NFInstances {
+		url, ok := c.nfURLfromProfile(inst, serviceName)
+		if !ok {
+			fmt.Printf("⚠️  %s instance %s has no usable endpoint, skipped\n", targetNFType, inst.NFInstanceID)
+			continue
+		}
+		endpoint := nfEndpoint(inst, serviceName, url)
 		if inst.NFStatus == "REGISTERED" {
-			if url, ok := c.nfURLfromProfile(inst); ok {
-				return url, nil
-			}
+			registered = append(registered, endpoint)
+		} else {
+			others = append(others, endpoint)
 		}
 	}
-	if url, ok := c.nfURLfromProfile(res.NFInstances[0]); ok {
-		fmt.Printf("⚠️  NF URL (fallback): %s\n", url)
-		return url, nil
+	if len(registered) > 0 {
+		return registered, nil
+	}
+	if len(others) > 0 {
+		fmt.Printf("⚠️  No REGISTERED %s instance, using the %d discovered (fallback)\n", targetNFType, len(others))
+		return others, nil
+	}
+	return nil, fmt.Errorf("no suitable ipEndPoint found")
+}
+
+// nfEndpoint describes an instance with the priority, capacity and load of the target
+// service, or of the profile when the service does not set them
+func nfEndpoint(p types.NFProfile, serviceName, url string) types.NFEndpoint {
+	endpoint := types.NFEndpoint{
+		InstanceID: p.NFInstanceID,
+		URL:        url,
+		Priority:   p.Priority,
+		Capacity:   p.Capacity,
+		Load:       p.Load,
+	}
+	for _, svc := range p.NFServices {
+		if serviceName == "" || svc.ServiceName != serviceName {
+			continue
+		}
+		if svc.Priority != 0 {
+			endpoint.Priority = svc.Priority
+		}
+		if svc.Capacity != 0 {
+			endpoint.Capacity = svc.Capacity
+		}
+		if svc.Load != 0 {
+			endpoint.Load = svc.Load
+		}
+		break
 	}
-	return "", fmt.Errorf("no suitable ipEndPoint found")
+	return endpoint
 }
 
-// NFDiscoveryURL is used by the benchmark runner.
+// NFDiscoveryInstances is used by the benchmark runner.
 // It reads human-friendly configuration nodes and launches discovery.
-func NFDiscoveryURL(
+func NFDiscoveryInstances(
 	cfg map[string]interface{},
-	targetNFType string,
-) (string, error) {
+	targetNFType, serviceName string, query url.Values,
+) ([]types.NFEndpoint, error) {
 
 	nrfURL, ok := getCfgString(cfg["nrf_url"])
 	if !ok {
-		return "", fmt.Errorf("nrf_url missing in configuration")
+		return nil, fmt.Errorf("nrf_url missing in configuration")
 	}
 	reqType, _ := getCfgString(cfg["requester_nf_type"])
 	reqID, _ := getCfgString(cfg["requester_nf_instance_id"])
 
+	cacheMode, err := discoveryCacheMode(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	client := NewNFDiscoveryClient(nrfURL, 10*time.Second)
-	url, err := client.DiscoverAndGetURL(targetNFType, reqType, reqID)
+	client.CacheMode = cacheMode
+	instances, err := client.DiscoverInstances(targetNFType, serviceName, reqType, reqID, query)
 	if err != nil {
 		fmt.Printf("❌ NF discovery error: %v\n", err)
-		return "", err
+		return nil, err
 	}
-	return url, nil
+	return instances, nil
 }
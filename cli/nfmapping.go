@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devuk0204/ctrlbench/types"
+	"gopkg.in/yaml.v3"
+)
+
+// NFMappingFile holds user overrides for the NF/service mapping of specification files
+const NFMappingFile = "nf_mapping.yaml"
+
+// nfMappingTemplate is written by -b when no mapping file exists yet
+const nfMappingTemplate = `# =============================================================================
+# NF MAPPING - Overrides for assigning specification files to NFs and services
+# =============================================================================
+# By default the NF is derived from the 3GPP file name (TS29xxx_N<nf>_<Service>),
+# then from the API name of the server URL ({apiRoot}/n<nf>-<service>/v1) and
+# finally from the service-level OAuth2 scope. Specs matching none of these are
+# listed under UNKNOWN (see 'ctrlbench lint').
+#
+# This file is never overwritten by -b. Changes apply on the next run.
+#
+# nfs:        NF token → NF name, for tokens that are not simply upper-cased
+#   5g-eir: 5G-EIR
+# files:      spec file name without extension → NF
+#   Vendor_AnalyticsExposure: NWDAF
+# services:   spec file name without extension → service name
+#   TS29510_Nnrf_AccessToken: NNRF-OAUTH2
+# =============================================================================
+
+nfs: {}
+files: {}
+services: {}
+`
+
+// LoadNFMapping reads nf_mapping.yaml if present
+func LoadNFMapping() (types.NFMapping, error) {
+	var mapping types.NFMapping
+
+	data, err := os.ReadFile(NFMappingFile)
+	if os.IsNotExist(err) {
+		return mapping, nil
+	}
+	if err != nil {
+		return mapping, fmt.Errorf("failed to read %s: %w", NFMappingFile, err)
+	}
+
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return mapping, fmt.Errorf("failed to parse %s: %w", NFMappingFile, err)
+	}
+
+	return mapping, nil
+}
+
+// writeNFMappingTemplate creates nf_mapping.yaml unless the user already has one
+func writeNFMappingTemplate() error {
+	if _, err := os.Stat(NFMappingFile); err == nil {
+		return nil
+	}
+
+	if err := os.WriteFile(NFMappingFile, []byte(nfMappingTemplate), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", NFMappingFile, err)
+	}
+
+	fmt.Printf("📄 Created %s for NF/service mapping overrides\n", NFMappingFile)
+	return nil
+}
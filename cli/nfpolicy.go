@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// ResolveNFPolicy reads the execution policy of an NF from nf_settings, with
+// global_settings as fallback for the timeout and the OAuth2 requester identity
+func ResolveNFPolicy(config *types.ConfigurationFile, nf string) (types.NFPolicy, error) {
+	global := config.UserInputs.GlobalSettings
+	settings := lookupNFSettings(config.UserInputs.NFSettings, nf)
+
+	policy := types.NFPolicy{NF: nf, Enabled: true}
+	if enabled, ok := cfgValue(settings["enabled"]).(bool); ok {
+		policy.Enabled = enabled
+	}
+
+	switch version := strings.ToLower(cfgText(settings["http_version"])); version {
+	case "":
+		policy.HTTPVersion = types.HTTPVersionDefault
+	case "1.1", "1", "http/1.1":
+		policy.HTTPVersion = types.HTTPVersion1
+	case "2", "2.0", "h2", "h2c", "http/2":
+		policy.HTTPVersion = types.HTTPVersion2
+	default:
+		return policy, fmt.Errorf("nf_settings.%s.http_version: unknown HTTP version '%s' (1.1, 2)", nf, version)
+	}
+
+	policy.BaseURL = trimSlashRight(cfgText(settings["base_url"]))
+	if policy.BaseURL != "" {
+		if parsed, err := url.Parse(policy.BaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return policy, fmt.Errorf("nf_settings.%s.base_url: '%s' is not an absolute URL", nf, policy.BaseURL)
+		}
+	}
+
+	switch mode := strings.ToLower(cfgText(settings["load_balancing"])); mode {
+	case "":
+		policy.LoadBalance = types.LoadBalanceRoundRobin
+	case types.LoadBalanceRoundRobin, types.LoadBalanceRandom, types.LoadBalanceWeighted, types.LoadBalanceFirst:
+		policy.LoadBalance = mode
+	default:
+		return policy, fmt.Errorf("nf_settings.%s.load_balancing: unknown mode '%s' (round_robin, random, weighted, first)", nf, mode)
+	}
+
+	// NF timeout, then the global timeout
+	for _, node := range []interface{}{settings["timeout_seconds"], global["timeout_seconds"]} {
+		if seconds, ok := cfgNumber(node); ok && seconds > 0 {
+			policy.Timeout = time.Duration(seconds * float64(time.Second))
+			break
+		}
+	}
+
+	if rate, ok := cfgNumber(settings["rate_limit_rps"]); ok {
+		if rate < 0 {
+			return policy, fmt.Errorf("nf_settings.%s.rate_limit_rps: must not be negative", nf)
+		}
+		policy.RateLimit = rate
+	}
+
+	policy.TLS = types.TLSPolicy{
+		CAFile:   cfgText(settings["tls_ca_file"]),
+		CertFile: cfgText(settings["tls_cert_file"]),
+		KeyFile:  cfgText(settings["tls_key_file"]),
+	}
+	policy.TLS.InsecureSkipVerify, _ = cfgValue(settings["tls_insecure_skip_verify"]).(bool)
+	if (policy.TLS.CertFile == "") != (policy.TLS.KeyFile == "") {
+		return policy, fmt.Errorf("nf_settings.%s: tls_cert_file and tls_key_file must be set together", nf)
+	}
+
+	policy.Auth = types.AuthPolicy{Mode: strings.ToLower(cfgText(settings["auth_mode"])), Token: cfgText(settings["auth_token"])}
+	switch scopes := strings.ToLower(cfgText(settings["auth_scopes"])); scopes {
+	case "":
+		policy.Auth.Scopes = types.AuthScopesOperation
+	case types.AuthScopesOperation, types.AuthScopesService:
+		policy.Auth.Scopes = scopes
+	default:
+		return policy, fmt.Errorf("nf_settings.%s.auth_scopes: unknown alternative '%s' (operation, service)", nf, scopes)
+	}
+
+	switch policy.Auth.Mode {
+	case "", types.AuthModeNone:
+		policy.Auth.Mode = types.AuthModeNone
+	case types.AuthModeBearer:
+		if policy.Auth.Token == "" {
+			return policy, fmt.Errorf("nf_settings.%s.auth_token: required for auth_mode bearer", nf)
+		}
+	case types.AuthModeOAuth2:
+		nrfURL, _ := getCfgString(global["nrf_url"])
+		if !strings.EqualFold(nf, "NRF") {
+			// Tokens are requested with the NRF's own policy
+			nrfPolicy, err := ResolveNFPolicy(config, "NRF")
+			if err != nil {
+				return policy, err
+			}
+			nrfPolicy.Auth = types.AuthPolicy{Mode: types.AuthModeNone}
+			if nrfPolicy.BaseURL != "" {
+				nrfURL = nrfPolicy.BaseURL
+			}
+			policy.Auth.NRF = &nrfPolicy
+		}
+		if nrfURL == "" {
+			return policy, fmt.Errorf("nf_settings.%s.auth_mode: oauth2 needs global_settings.nrf_url", nf)
+		}
+		policy.Auth.TokenURL = trimSlashRight(nrfURL) + "/oauth2/token"
+		policy.Auth.RequesterNFType = cfgText(global["requester_nf_type"])
+		policy.Auth.RequesterNFInstanceID = cfgText(global["requester_nf_instance_id"])
+	default:
+		return policy, fmt.Errorf("nf_settings.%s.auth_mode: unknown mode '%s' (none, bearer, oauth2)", nf, policy.Auth.Mode)
+	}
+
+	return policy, nil
+}
+
+// DescribeNFPolicy summarizes a policy in one line
+func DescribeNFPolicy(policy types.NFPolicy) string {
+	parts := []string{"http " + policy.HTTPVersion}
+	if policy.HTTPVersion == types.HTTPVersionDefault {
+		parts[0] = "http default"
+	}
+	if policy.BaseURL != "" {
+		parts = append(parts, "base_url "+policy.BaseURL)
+	} else {
+		parts = append(parts, "balance "+policy.LoadBalance)
+	}
+	if policy.Timeout > 0 {
+		parts = append(parts, fmt.Sprintf("timeout %v", policy.Timeout))
+	}
+	if policy.RateLimit > 0 {
+		parts = append(parts, fmt.Sprintf("rate %g/s", policy.RateLimit))
+	}
+	if policy.TLS.InsecureSkipVerify || policy.TLS.CAFile != "" || policy.TLS.CertFile != "" {
+		parts = append(parts, "custom tls")
+	}
+	if policy.Auth.Mode == types.AuthModeOAuth2 {
+		parts = append(parts, policy.Auth.Scopes+" scopes")
+	}
+	return strings.Join(append(parts, "auth "+policy.Auth.Mode), ", ")
+}
+
+// lookupNFSettings finds the nf_settings block of an NF, ignoring case
+func lookupNFSettings(nfSettings map[string]map[string]interface{}, nf string) map[string]interface{} {
+	if settings, exists := nfSettings[nf]; exists {
+		return settings
+	}
+	for name, settings := range nfSettings {
+		if strings.EqualFold(name, nf) {
+			return settings
+		}
+	}
+	return nil
+}
+
+// cfgValue returns cfg.<key>.value if the node is a map, otherwise the raw node
+func cfgValue(node interface{}) interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		return m["value"]
+	}
+	return node
+}
+
+// cfgText returns a scalar configuration value as a string, empty when unset
+func cfgText(node interface{}) string {
+	switch value := cfgValue(node).(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// cfgNumber returns a numeric configuration value
+func cfgNumber(node interface{}) (float64, bool) {
+	switch value := cfgValue(node).(type) {
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// newPolicyHTTPClient creates the HTTP client for the requests of a policy
+func newPolicyHTTPClient(policy types.NFPolicy, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	protocols := new(http.Protocols)
+	switch policy.HTTPVersion {
+	case types.HTTPVersion1:
+		protocols.SetHTTP1(true)
+		transport.Protocols = protocols
+	case types.HTTPVersion2:
+		// SBI over http uses HTTP/2 with prior knowledge (h2c)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		transport.Protocols = protocols
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: policy.TLS.InsecureSkipVerify}
+	if policy.TLS.CAFile != "" {
+		pem, err := os.ReadFile(policy.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls_ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls_ca_file %s holds no PEM certificate", policy.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if policy.TLS.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(policy.TLS.CertFile, policy.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls_cert_file/tls_key_file: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if policy.Timeout > 0 {
+		timeout = policy.Timeout
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// rateLimiter spaces requests evenly to stay under a requests-per-second cap
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter creates a limiter, nil when rps is unlimited
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// Wait blocks until the next request may be sent
+func (l *rateLimiter) Wait() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+// accessToken is a cached OAuth2 access token
+type accessToken struct {
+	value   string
+	expires time.Time
+}
+
+// requestAccessToken requests an OAuth2 access token from the NRF with the client
+// credentials grant of TS 29.510 (AccessTokenReq)
+func requestAccessToken(client *http.Client, policy types.NFPolicy, scopes []string) (accessToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("nfInstanceId", policy.Auth.RequesterNFInstanceID)
+	form.Set("nfType", policy.Auth.RequesterNFType)
+	form.Set("targetNfType", policy.NF)
+	form.Set("scope", strings.Join(scopes, " "))
+
+	resp, err := client.PostForm(policy.Auth.TokenURL, form)
+	if err != nil {
+		return accessToken{}, fmt.Errorf("access token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return accessToken{}, fmt.Errorf("read access token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return accessToken{}, fmt.Errorf("access token request failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var rsp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &rsp); err != nil || rsp.AccessToken == "" {
+		return accessToken{}, fmt.Errorf("parse access token response: %s", body)
+	}
+
+	token := accessToken{value: rsp.AccessToken}
+	if rsp.ExpiresIn > 0 {
+		// Renew shortly before the NRF lets it expire
+		token.expires = time.Now().Add(time.Duration(rsp.ExpiresIn)*time.Second - 5*time.Second)
+	}
+	return token, nil
+}
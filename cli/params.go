@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// SerializedParameters holds parameters serialized per OpenAPI style/explode rules
+type SerializedParameters struct {
+	PathValues map[string]string // Placeholder name → serialized path segment
+	Query      string            // Encoded query string without leading '?'
+	Headers    map[string]string // Header parameters, including the Cookie header
+}
+
+// SerializeParameters serializes typed parameter values by location, style, explode and content
+func SerializeParameters(params []types.ParameterValue) (*SerializedParameters, error) {
+	result := &SerializedParameters{
+		PathValues: make(map[string]string),
+		Headers:    make(map[string]string),
+	}
+
+	var queryParts []string
+	var cookieParts []string
+
+	for _, p := range params {
+		if IsEmptyValue(p.Value) {
+			continue // Skip empty optional parameters
+		}
+
+		// Parameters declared with content are encoded as a single media-type value
+		value := p.Value
+		if p.ContentType != "" {
+			encoded, err := encodeParameterContent(p.ContentType, value)
+			if err != nil {
+				return nil, fmt.Errorf("parameter '%s': %w", p.Name, err)
+			}
+			value = encoded
+		}
+
+		switch p.In {
+		case "path":
+			result.PathValues[p.Name] = serializePathValue(p, value)
+		case "header":
+			result.Headers[p.Name] = serializeSimple(value, explodeOrDefault(p, false), false)
+		case "cookie":
+			cookieParts = append(cookieParts, serializeCookieValue(p, value)...)
+		default:
+			parts, err := serializeQueryValue(p, value)
+			if err != nil {
+				return nil, err
+			}
+			queryParts = append(queryParts, parts...)
+		}
+	}
+
+	result.Query = strings.Join(queryParts, "&")
+	if len(cookieParts) > 0 {
+		result.Headers["Cookie"] = strings.Join(cookieParts, "; ")
+	}
+
+	return result, nil
+}
+
+// encodeParameterContent encodes a value for a parameter declared with content (e.g. application/json).
+// Strings that already hold JSON are sent unchanged.
+func encodeParameterContent(contentType string, value interface{}) (interface{}, error) {
+	if !strings.Contains(contentType, "json") {
+		return fmt.Sprintf("%v", value), nil
+	}
+
+	if str, ok := value.(string); ok && json.Valid([]byte(str)) {
+		return str, nil
+	}
+
+	data, err := json.Marshal(normalizeJSONValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s content: %w", contentType, err)
+	}
+	return string(data), nil
+}
+
+// explodeOrDefault returns the parameter's explode flag or the style default
+func explodeOrDefault(p types.ParameterValue, defaultExplode bool) bool {
+	if p.Explode != nil {
+		return *p.Explode
+	}
+	return defaultExplode
+}
+
+// serializePathValue serializes a path parameter (simple, label or matrix style)
+func serializePathValue(p types.ParameterValue, value interface{}) string {
+	explode := explodeOrDefault(p, false)
+
+	switch p.Style {
+	case "label":
+		if explode {
+			return "." + serializeSimpleWith(value, true, ".", true)
+		}
+		return "." + serializeSimple(value, false, true)
+	case "matrix":
+		return serializeMatrix(p.Name, value, explode)
+	default:
+		return serializeSimple(value, explode, true)
+	}
+}
+
+// serializeQueryValue serializes a query parameter (form, spaceDelimited, pipeDelimited or deepObject style)
+func serializeQueryValue(p types.ParameterValue, value interface{}) ([]string, error) {
+	style := p.Style
+	if style == "" {
+		style = "form"
+	}
+	explode := explodeOrDefault(p, style == "form")
+	name := url.QueryEscape(p.Name)
+
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = url.QueryEscape(scalarString(item))
+		}
+		if explode {
+			parts := make([]string, len(items))
+			for i, item := range items {
+				parts[i] = name + "=" + item
+			}
+			return parts, nil
+		}
+		switch style {
+		case "spaceDelimited":
+			return []string{name + "=" + strings.Join(items, "%20")}, nil
+		case "pipeDelimited":
+			return []string{name + "=" + strings.Join(items, "|")}, nil
+		default:
+			return []string{name + "=" + strings.Join(items, ",")}, nil
+		}
+	case map[string]interface{}:
+		keys := sortedMapKeys(v)
+		switch {
+		case style == "deepObject":
+			parts := make([]string, len(keys))
+			for i, key := range keys {
+				parts[i] = name + "%5B" + url.QueryEscape(key) + "%5D=" + url.QueryEscape(scalarString(v[key]))
+			}
+			return parts, nil
+		case explode:
+			parts := make([]string, len(keys))
+			for i, key := range keys {
+				parts[i] = url.QueryEscape(key) + "=" + url.QueryEscape(scalarString(v[key]))
+			}
+			return parts, nil
+		default:
+			var items []string
+			for _, key := range keys {
+				items = append(items, url.QueryEscape(key), url.QueryEscape(scalarString(v[key])))
+			}
+			return []string{name + "=" + strings.Join(items, ",")}, nil
+		}
+	default:
+		return []string{name + "=" + url.QueryEscape(scalarString(value))}, nil
+	}
+}
+
+// serializeCookieValue serializes a cookie parameter (form style)
+func serializeCookieValue(p types.ParameterValue, value interface{}) []string {
+	if explodeOrDefault(p, true) {
+		switch v := value.(type) {
+		case []interface{}:
+			parts := make([]string, len(v))
+			for i, item := range v {
+				parts[i] = p.Name + "=" + scalarString(item)
+			}
+			return parts
+		case map[string]interface{}:
+			var parts []string
+			for _, key := range sortedMapKeys(v) {
+				parts = append(parts, key+"="+scalarString(v[key]))
+			}
+			return parts
+		}
+	}
+	return []string{p.Name + "=" + serializeSimple(value, false, false)}
+}
+
+// serializeSimple serializes a value with the simple style
+func serializeSimple(value interface{}, explode, escape bool) string {
+	return serializeSimpleWith(value, explode, ",", escape)
+}
+
+// serializeSimpleWith serializes arrays and objects with the given item separator
+func serializeSimpleWith(value interface{}, explode bool, separator string, escape bool) string {
+	encode := func(s string) string {
+		if escape {
+			return url.PathEscape(s)
+		}
+		return s
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = encode(scalarString(item))
+		}
+		return strings.Join(items, separator)
+	case map[string]interface{}:
+		var items []string
+		for _, key := range sortedMapKeys(v) {
+			if explode {
+				items = append(items, encode(key)+"="+encode(scalarString(v[key])))
+			} else {
+				items = append(items, encode(key), encode(scalarString(v[key])))
+			}
+		}
+		if explode {
+			return strings.Join(items, separator)
+		}
+		return strings.Join(items, ",")
+	default:
+		return encode(scalarString(value))
+	}
+}
+
+// serializeMatrix serializes a path parameter with the matrix style
+func serializeMatrix(name string, value interface{}, explode bool) string {
+	switch v := value.(type) {
+	case []interface{}:
+		if explode {
+			var b strings.Builder
+			for _, item := range v {
+				b.WriteString(";" + name + "=" + url.PathEscape(scalarString(item)))
+			}
+			return b.String()
+		}
+	case map[string]interface{}:
+		if explode {
+			var b strings.Builder
+			for _, key := range sortedMapKeys(v) {
+				b.WriteString(";" + url.PathEscape(key) + "=" + url.PathEscape(scalarString(v[key])))
+			}
+			return b.String()
+		}
+	}
+	return ";" + name + "=" + serializeSimple(value, false, true)
+}
+
+// scalarString converts a scalar configuration value to its string form
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(normalizeJSONValue(v))
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// normalizeJSONValue converts YAML-decoded maps into JSON-encodable maps
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = normalizeJSONValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeJSONValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeJSONValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// IsEmptyValue checks if a configuration value is unset
+func IsEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// sortedMapKeys returns map keys in sorted order
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// ParseReleaseFlag parses the -r flag value. It accepts a default release
+// and/or per-NF selections: "rel-17", "AMF=rel-16,SMF=rel-17" or "rel-17,AMF=rel-16".
+func ParseReleaseFlag(value string) (types.ReleaseSelection, error) {
+	selection := types.ReleaseSelection{PerNF: make(map[string]string)}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		nf, release, found := strings.Cut(item, "=")
+		if !found {
+			if selection.Default != "" && selection.Default != item {
+				return selection, fmt.Errorf("multiple default releases in '%s'", value)
+			}
+			selection.Default = item
+			continue
+		}
+
+		nf = strings.ToUpper(strings.TrimSpace(nf))
+		release = strings.TrimSpace(release)
+		if nf == "" || release == "" {
+			return selection, fmt.Errorf("invalid release selection '%s' (expected NF=release)", item)
+		}
+		selection.PerNF[nf] = release
+	}
+
+	return selection, nil
+}
+
+// LoadReleaseSelection combines release settings from configuration.yaml
+// (global_settings.release, nf_settings.<NF>.release) with the -r flag, the flag taking precedence
+func LoadReleaseSelection(flagValue string) (types.ReleaseSelection, error) {
+	flagSelection, err := ParseReleaseFlag(flagValue)
+	if err != nil {
+		return flagSelection, err
+	}
+
+	selection := loadConfiguredReleases()
+
+	if flagSelection.Default != "" {
+		selection.Default = flagSelection.Default
+		// An explicit default on the command line replaces configured per-NF choices
+		selection.PerNF = make(map[string]string)
+	}
+	for nf, release := range flagSelection.PerNF {
+		selection.PerNF[nf] = release
+	}
+
+	return selection, nil
+}
+
+// loadConfiguredReleases reads release settings from configuration.yaml if present
+func loadConfiguredReleases() types.ReleaseSelection {
+	selection := types.ReleaseSelection{PerNF: make(map[string]string)}
+
+	if _, err := os.Stat(ConfigurationPath()); err != nil {
+		return selection
+	}
+
+	config, err := LoadEffectiveConfiguration()
+	if err != nil {
+		fmt.Printf("⚠️  Ignoring release settings in %s:\n%v\n", ConfigurationPath(), err)
+		return selection
+	}
+
+	if release, ok := getCfgString(config.UserInputs.GlobalSettings["release"]); ok {
+		selection.Default = release
+	}
+
+	for nf, settings := range config.UserInputs.NFSettings {
+		if release, ok := getCfgString(settings["release"]); ok && release != "" {
+			selection.PerNF[strings.ToUpper(nf)] = release
+		}
+	}
+
+	return selection
+}
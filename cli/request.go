@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// templateSentinel stands in for a templated value while a request is serialized once.
+// It is plain alphanumeric text so URL and JSON encoding leave it unchanged.
+const templateSentinel = "ctrlbenchtmpl"
+
+var (
+	sentinelPattern     = regexp.MustCompile(templateSentinel + `(\d+)x`)
+	bodySentinelPattern = regexp.MustCompile(`"` + templateSentinel + `(\d+)x"`)
+)
+
+// preparedRequest is an API request serialized once. Templated values are left as
+// slots that are evaluated per request, requests without templates are sent as built.
+type preparedRequest struct {
+	url         *valueTemplate
+	headers     map[string]*valueTemplate
+	body        *valueTemplate // nil without request body
+	expressions int            // Templated values evaluated per request
+	dataset     *subscriberDataset
+
+	staticHeaders map[string]string
+	staticBody    []byte
+	seq           atomic.Int64
+}
+
+// renderedRequest is one request ready to be sent
+type renderedRequest struct {
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+// request returns the prepared request of an API, preparing it on first use
+func (e *APIExecutor) request(execInfo *types.APIExecutionInfo) (*preparedRequest, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if request, exists := e.requests[execInfo]; exists {
+		return request, nil
+	}
+	request, err := e.prepareRequest(execInfo)
+	if err != nil {
+		return nil, err
+	}
+	e.requests[execInfo] = request
+	return request, nil
+}
+
+// prepareRequest serializes the URL, headers and body of an API and compiles their templates
+func (e *APIExecutor) prepareRequest(execInfo *types.APIExecutionInfo) (*preparedRequest, error) {
+	request := &preparedRequest{headers: make(map[string]*valueTemplate), dataset: e.dataset}
+	var slots []*valueTemplate
+
+	// Parameters are serialized with sentinels, which are then escaped per request
+	info := *execInfo
+	info.Parameters = make([]types.ParameterValue, len(execInfo.Parameters))
+	for i, p := range execInfo.Parameters {
+		value, err := substituteTemplates(p.Value, &slots)
+		if err != nil {
+			return nil, fmt.Errorf("parameter '%s': %w", p.Name, err)
+		}
+		p.Value = value
+		info.Parameters[i] = p
+	}
+	fullURL, err := e.buildFinalURL(&info)
+	if err != nil {
+		return nil, err
+	}
+	query := strings.Index(fullURL, "?")
+	request.url = spliceTemplates(fullURL, sentinelPattern, slots, func(offset int, slot *valueTemplate) func(string) string {
+		if query >= 0 && offset > query {
+			return url.QueryEscape
+		}
+		return url.PathEscape
+	})
+
+	for key, value := range execInfo.Headers {
+		header := literalTemplate(value)
+		if hasTemplate(value) {
+			if header, err = compileTemplate(value); err != nil {
+				return nil, fmt.Errorf("header '%s': %w", key, err)
+			}
+			request.expressions++
+			if err := e.dataset.checkColumns(header.columns); err != nil {
+				return nil, fmt.Errorf("header '%s': %w", key, err)
+			}
+		}
+		request.headers[key] = header
+	}
+
+	if execInfo.RequestBody != nil {
+		body, err := substituteTemplates(execInfo.RequestBody, &slots)
+		if err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		if execInfo.ContentType == types.ContentTypeMultipart {
+			var contentType string
+			if data, contentType, err = encodeMultipart(data, execInfo.Parts); err != nil {
+				return nil, fmt.Errorf("failed to encode multipart body: %w", err)
+			}
+			request.headers["Content-Type"] = literalTemplate(contentType)
+		}
+		request.body = spliceTemplates(string(data), bodySentinelPattern, slots, func(offset int, slot *valueTemplate) func(string) string {
+			if slot.IsNumeric() {
+				return nil // Sent as a JSON number
+			}
+			return jsonString
+		})
+	}
+	request.expressions += len(slots)
+	for _, slot := range slots {
+		if err := e.dataset.checkColumns(slot.columns); err != nil {
+			return nil, err
+		}
+	}
+
+	// Static parts are rendered once
+	if request.headersStatic() {
+		request.staticHeaders = request.renderHeaders(nil)
+	}
+	if request.body != nil && request.body.IsStatic() {
+		request.staticBody = []byte(request.body.Render(nil))
+	}
+	return request, nil
+}
+
+// render evaluates the templates of the prepared request for its next request
+func (r *preparedRequest) render(worker int) renderedRequest {
+	rendered := renderedRequest{headers: r.staticHeaders, body: r.staticBody}
+	if r.expressions == 0 {
+		rendered.url = r.url.Render(nil)
+		return rendered
+	}
+
+	ctx := &templateContext{seq: r.seq.Add(1), worker: worker, now: time.Now(), dataset: r.dataset}
+	rendered.url = r.url.Render(ctx)
+	if rendered.headers == nil {
+		rendered.headers = r.renderHeaders(ctx)
+	}
+	if rendered.body == nil && r.body != nil {
+		rendered.body = []byte(r.body.Render(ctx))
+	}
+	return rendered
+}
+
+// SampleRequest evaluates the parameters and body of an API as its first request would
+// send them, without advancing counters. The subscriber is the one the first request draws.
+func (e *APIExecutor) SampleRequest(execInfo *types.APIExecutionInfo) ([]types.ParameterValue, interface{}, error) {
+	ctx := &templateContext{seq: 1, now: time.Now(), dataset: e.dataset}
+	if e.dataset != nil {
+		ctx.row = e.dataset.peek()
+	}
+
+	params := make([]types.ParameterValue, len(execInfo.Parameters))
+	for i, p := range execInfo.Parameters {
+		value, err := renderValue(p.Value, ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parameter '%s': %w", p.Name, err)
+		}
+		p.Value = value
+		params[i] = p
+	}
+	body, err := renderValue(execInfo.RequestBody, ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request body: %w", err)
+	}
+	return params, body, nil
+}
+
+// renderValue copies a value, evaluating its templated strings. Numeric expressions become numbers.
+func renderValue(value interface{}, ctx *templateContext) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !hasTemplate(v) {
+			return v, nil
+		}
+		t, err := compileTemplate(v)
+		if err != nil {
+			return nil, err
+		}
+		text := t.Render(ctx)
+		if t.IsNumeric() {
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				return number, nil
+			}
+		}
+		return text, nil
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, ctx)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = rendered
+		}
+		return items, nil
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			rendered, err := renderValue(field, ctx)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = rendered
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// headersStatic checks if no header holds a template
+func (r *preparedRequest) headersStatic() bool {
+	for _, header := range r.headers {
+		if !header.IsStatic() {
+			return false
+		}
+	}
+	return true
+}
+
+// renderHeaders evaluates the header templates
+func (r *preparedRequest) renderHeaders(ctx *templateContext) map[string]string {
+	headers := make(map[string]string, len(r.headers))
+	for key, header := range r.headers {
+		headers[key] = header.Render(ctx)
+	}
+	return headers
+}
+
+// substituteTemplates copies a value, replacing templated strings with numbered sentinels
+func substituteTemplates(value interface{}, slots *[]*valueTemplate) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !hasTemplate(v) {
+			return v, nil
+		}
+		slot, err := compileTemplate(v)
+		if err != nil {
+			return nil, err
+		}
+		*slots = append(*slots, slot)
+		return fmt.Sprintf("%s%dx", templateSentinel, len(*slots)-1), nil
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			substituted, err := substituteTemplates(item, slots)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = substituted
+		}
+		return items, nil
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			substituted, err := substituteTemplates(field, slots)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = substituted
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// spliceTemplates splits serialized text at its sentinels. Each sentinel becomes a part
+// evaluating its slot, encoded by the function chosen for its position (nil sends it as is).
+func spliceTemplates(text string, pattern *regexp.Regexp, slots []*valueTemplate, encoding func(offset int, slot *valueTemplate) func(string) string) *valueTemplate {
+	t := &valueTemplate{}
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		index, err := strconv.Atoi(text[match[2]:match[3]])
+		if err != nil || index >= len(slots) {
+			continue
+		}
+		if match[0] > last {
+			t.parts = append(t.parts, templatePart{literal: text[last:match[0]]})
+		}
+
+		slot, encode := slots[index], encoding(match[0], slots[index])
+		eval := slot.Render
+		if encode != nil {
+			eval = func(ctx *templateContext) string { return encode(slot.Render(ctx)) }
+		}
+		t.parts = append(t.parts, templatePart{source: slot.String(), eval: eval, numeric: slot.IsNumeric()})
+		last = match[1]
+	}
+	if last < len(text) || len(t.parts) == 0 {
+		t.parts = append(t.parts, templatePart{literal: text[last:]})
+	}
+	return t
+}
+
+// jsonString encodes text as a JSON string
+func jsonString(text string) string {
+	data, _ := json.Marshal(text)
+	return string(data)
+}
@@ -12,11 +12,22 @@ import (
 func PrintUsage() {
 	fmt.Println("💡 Usage:")
 	fmt.Println("    ctrlbench -t NF_NAME -a \"API_NAME\" -i 100")
+	fmt.Println("    ctrlbench -t NF_NAME -s SERVICE -a \"API_NAME\"   # Disambiguate APIs with the same name")
+	fmt.Println("    ctrlbench -t NF_NAME -a \"API_NAME\" -force  # Send even if the pre-flight schema check fails")
+	fmt.Println("    ctrlbench -t NF_NAME -a \"API_NAME\" -refresh-discovery  # Ignore cached NRF discovery results")
 	fmt.Println("    ctrlbench -h              # Show usage only")
 	fmt.Println("    ctrlbench -h all          # Show all NFs and APIs")
 	fmt.Println("    ctrlbench -h NF_NAME      # Show specific NF APIs")
 	fmt.Println("    ctrlbench -b              # Build configuration file for all NFs")
 	fmt.Println("    ctrlbench -b NF_NAME      # Build configuration file for specific NF")
+	fmt.Println("    ctrlbench -b -f           # Rebuild from scratch (entered values are kept by default)")
+	fmt.Println("    ctrlbench lint [-format json] # Report what the parser rejects or mis-handles in openapi/")
+	fmt.Println("    ctrlbench validate [NF] [-format json] # Check configuration.yaml against every API before a run")
+	fmt.Println("    ctrlbench -r rel-17 ...   # Use a release bundle (openapi/rel-17), or -r AMF=rel-16,SMF=rel-17")
+	fmt.Println("    ctrlbench -c lab2.yaml -set user_inputs.common_parameters.supi=imsi-001010000000001 ...")
+	fmt.Println("    ctrlbench -profile lab-free5gc ...  # Use a profile of configuration.yaml (or CTRLBENCH_PROFILE)")
+	fmt.Println("    ctrlbench config show [--effective] # List configured values and where each came from")
+	fmt.Println("    ctrlbench -c lab2.yaml config show  # -c, -profile and -set may precede validate and config")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("    ctrlbench -t AUSF -a \"CreateUe-Authentications\" -i 10")
@@ -24,6 +35,23 @@ func PrintUsage() {
 	fmt.Println()
 	fmt.Println("Note: You must build the configuration file first using -b option before executing APIs.")
 	fmt.Println("Note: NRF URL must be configured in configuration.yaml")
+	fmt.Println("Note: nf_settings.<NF> sets enabled, HTTP version, base_url, timeout, rate cap, TLS and auth per NF.")
+	fmt.Println("Note: NF/service mapping can be overridden in nf_mapping.yaml (created by -b, never overwritten).")
+	fmt.Println("Note: Configuration values can be overridden by CTRLBENCH_GLOBAL_<SETTING>, CTRLBENCH_NF_<NF>_<SETTING>")
+	fmt.Println("      and CTRLBENCH_PARAM_<NAME> environment variables, then by -set flags.")
+	fmt.Println("Note: Values may hold {{uuid}}, {{seq}}, {{worker}}, {{now.rfc3339}}, {{randInt 1 255}} or")
+	fmt.Println("      {{supi from 208930000000001 step 1}}, evaluated for every request.")
+	fmt.Println("Note: {{subscriber.supi}}, {{subscriber.gpsi}}, ... draw subscribers from global_settings.dataset_file")
+	fmt.Println("      (CSV or YAML) in sequential, random, uniform or zipf order.")
+	fmt.Println("Note: api_specific_request_bodies.<NF>/<API>.content_type picks merge-patch, json-patch or multipart/related,")
+	fmt.Println("      multipart binary parts come from 'parts' (file or hex).")
+	fmt.Println("Note: NRF discovery results are reused until their validityPeriod expires, within a run or across runs")
+	fmt.Println("      with global_settings.discovery_cache: disk (" + DiscoveryCachePath + ").")
+	fmt.Println("Note: nf_settings.<NF>.discovery_query adds service-names, snssais, dnn, target-plmn-list, ...")
+	fmt.Println("      to the NRF discovery of that NF.")
+	fmt.Println("Note: Requests are spread across all discovered instances per nf_settings.<NF>.load_balancing")
+	fmt.Println("      (round_robin, random, weighted, first) and results are reported per NF instance ID.")
+	fmt.Println("Note: Parsed specifications are cached in .ctrlbench/ and rebuilt when a spec file changes.")
 }
 
 // ShowHelp displays help information for services and APIs
@@ -49,7 +77,11 @@ func showAllNFs(services map[string]types.ServiceMetadata) {
 			totalAPIs += len(service.APIs)
 		}
 
-		fmt.Printf("📁 %s (%d services, %d APIs)\n", nf, len(serviceList), totalAPIs)
+		if release := serviceList[0].Release; release != "" {
+			fmt.Printf("📁 %s (%d services, %d APIs) [%s]\n", nf, len(serviceList), totalAPIs, release)
+		} else {
+			fmt.Printf("📁 %s (%d services, %d APIs)\n", nf, len(serviceList), totalAPIs)
+		}
 	}
 
 	fmt.Println("\n💡 Use -h NF_NAME to see detailed APIs for a specific NF")
@@ -74,10 +106,20 @@ func showSpecificNF(services map[string]types.ServiceMetadata, nfFilter string)
 		return
 	}
 
+	ambiguousAPIs := findAmbiguousAPINames(matchedServices)
+
+	sort.Slice(matchedServices, func(i, j int) bool {
+		return matchedServices[i].Name < matchedServices[j].Name
+	})
+
 	for _, service := range matchedServices {
 		servicePath := ExtractServicePath(service)
 
-		fmt.Printf("📂 %s [%s]\n", CleanServiceName(service.Name), servicePath)
+		if service.Release != "" {
+			fmt.Printf("📂 %s [%s] (%s, version %s)\n", CleanServiceName(service.Name), FormatServicePath(servicePath), service.Release, service.Server.FullVersion)
+		} else {
+			fmt.Printf("📂 %s [%s] (version %s)\n", CleanServiceName(service.Name), FormatServicePath(servicePath), service.Server.FullVersion)
+		}
 
 		apiNames := getSortedKeys(service.APIs)
 		for _, apiName := range apiNames {
@@ -86,6 +128,9 @@ func showSpecificNF(services map[string]types.ServiceMetadata, nfFilter string)
 			cleanName := CleanAPIName(apiName)
 
 			fmt.Printf("    📄 %s\n", cleanName)
+			if ambiguousAPIs[cleanName] {
+				fmt.Printf("        Key: %s (name is ambiguous, use -s %s or this key)\n", api.Key, CleanServiceName(service.Name))
+			}
 			fmt.Printf("        Method: %s\n", method)
 			fmt.Printf("        Path: %s\n", api.Path)
 
@@ -104,30 +149,103 @@ func showSpecificNF(services map[string]types.ServiceMetadata, nfFilter string)
 					fmt.Printf("        Request Body: %s\n", api.RequestBody)
 				}
 			}
+			if len(api.RequestContentTypes) > 1 || (len(api.RequestContentTypes) == 1 && api.RequestContentTypes[0] != types.ContentTypeJSON) {
+				fmt.Printf("        Content Types: %s\n", strings.Join(api.RequestContentTypes, ", "))
+			}
+
+			// Show OAuth2 scopes required by this API
+			if sets := ExtractScopeSets(api.Security); len(sets) > 0 {
+				if IsAnonymousAccessAllowed(api.Security) {
+					fmt.Printf("        Scopes: %s (token optional)\n", FormatScopeSets(sets))
+				} else {
+					fmt.Printf("        Scopes: %s\n", FormatScopeSets(sets))
+				}
+			}
+
+			// Show notifications triggered by this API
+			if len(api.Callbacks) > 0 {
+				fmt.Printf("        Notifications:\n")
+				for _, callback := range api.Callbacks {
+					fmt.Printf("            🔔 %s\n", formatCallback(callback))
+				}
+			}
 			fmt.Println()
 		}
 	}
+
+	showNotificationSummary(matchedServices)
+}
+
+// findAmbiguousAPINames - Find API names that are defined by more than one service
+func findAmbiguousAPINames(serviceList []types.ServiceMetadata) map[string]bool {
+	owners := make(map[string]int)
+	for _, service := range serviceList {
+		for apiName := range service.APIs {
+			owners[CleanAPIName(apiName)]++
+		}
+	}
+
+	ambiguous := make(map[string]bool)
+	for name, count := range owners {
+		if count > 1 {
+			ambiguous[name] = true
+		}
+	}
+	return ambiguous
+}
+
+// showNotificationSummary - List which subscription APIs trigger which notifications
+func showNotificationSummary(serviceList []types.ServiceMetadata) {
+	var lines []string
+
+	for _, service := range serviceList {
+		for _, apiName := range getSortedKeys(service.APIs) {
+			api := service.APIs[apiName]
+			for _, callback := range api.Callbacks {
+				lines = append(lines, fmt.Sprintf("    %s.%s → %s (%s)",
+					CleanServiceName(service.Name), CleanAPIName(apiName), callback.Name, callback.PayloadSchema))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Println("🔔 Notification Triggers")
+	fmt.Println(strings.Repeat("=", 50))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+// formatCallback - Format callback as "name: METHOD url (payload) → responses"
+func formatCallback(callback types.CallbackMetadata) string {
+	result := fmt.Sprintf("%s: %s %s", callback.Name, callback.Method, callback.URLExpression)
+	if callback.PayloadSchema != "" {
+		result += fmt.Sprintf(" (payload: %s)", callback.PayloadSchema)
+	}
+
+	var successCodes []string
+	for _, code := range callback.Responses {
+		if strings.HasPrefix(code, "2") {
+			successCodes = append(successCodes, code)
+		}
+	}
+	if len(successCodes) > 0 {
+		result += fmt.Sprintf(" → expects %s", strings.Join(successCodes, "/"))
+	}
+
+	return result
 }
 
 // getRequiredParameters - Extract only required parameters
 func getRequiredParameters(api types.APIMetadata, service types.ServiceMetadata) []string {
 	var requiredParams []string
 
-	if service.OpenAPISpec != nil {
-		// Find required parameters from OpenAPI spec
-		for _, pathItem := range service.OpenAPISpec.Paths {
-			if pathItem.Get != nil && pathItem.Get.OperationID == strings.TrimSuffix(api.Name, " [GET]") {
-				requiredParams = extractRequiredParamsFromOperation(pathItem.Get)
-			} else if pathItem.Post != nil && pathItem.Post.OperationID == strings.TrimSuffix(api.Name, " [POST]") {
-				requiredParams = extractRequiredParamsFromOperation(pathItem.Post)
-			} else if pathItem.Put != nil && pathItem.Put.OperationID == strings.TrimSuffix(api.Name, " [PUT]") {
-				requiredParams = extractRequiredParamsFromOperation(pathItem.Put)
-			} else if pathItem.Delete != nil && pathItem.Delete.OperationID == strings.TrimSuffix(api.Name, " [DELETE]") {
-				requiredParams = extractRequiredParamsFromOperation(pathItem.Delete)
-			} else if pathItem.Patch != nil && pathItem.Patch.OperationID == strings.TrimSuffix(api.Name, " [PATCH]") {
-				requiredParams = extractRequiredParamsFromOperation(pathItem.Patch)
-			}
-		}
+	if operation := FindOperation(service, api); operation != nil {
+		requiredParams = extractRequiredParamsFromOperation(operation)
 	}
 
 	// If not found in OpenAPI, consider path parameters as required by default
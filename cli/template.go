@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// Template expressions available in configuration values
+var templateExpressions = []string{
+	"uuid", "seq [from N] [step M]", "worker", "now.rfc3339", "now.rfc3339nano", "now.unix", "now.unixMilli",
+	"randInt MIN MAX", "supi from N [step M]", "gpsi from N [step M]", "subscriber.COLUMN",
+}
+
+// templateContext is what the expressions of one request are evaluated against
+type templateContext struct {
+	seq    int64 // Request number of the API, starting at 1
+	worker int   // Worker sending the request
+	now    time.Time
+
+	dataset *subscriberDataset
+	row     types.Subscriber // Drawn from the dataset on first use
+}
+
+// subscriber returns a column of the request's subscriber, the same row for every expression
+func (ctx *templateContext) subscriber(column string) string {
+	if ctx.row == nil && ctx.dataset != nil {
+		ctx.row = ctx.dataset.draw()
+	}
+	return ctx.row[column]
+}
+
+// templateFunc evaluates one expression
+type templateFunc func(ctx *templateContext) string
+
+// templatePart is literal text or an expression
+type templatePart struct {
+	literal string
+	source  string // How the expression is shown, empty for literal text
+	eval    templateFunc
+	numeric bool
+}
+
+// valueTemplate is a configuration value compiled into literal text and expressions
+type valueTemplate struct {
+	parts   []templatePart
+	columns []string // Subscriber dataset columns used
+}
+
+// hasTemplate checks if a string holds a template expression
+func hasTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// literalTemplate creates a template without expressions
+func literalTemplate(text string) *valueTemplate {
+	return &valueTemplate{parts: []templatePart{{literal: text}}}
+}
+
+// compileTemplate compiles the {{...}} expressions of a configuration value
+func compileTemplate(text string) (*valueTemplate, error) {
+	t := &valueTemplate{}
+	for rest := text; rest != ""; {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated template expression in '%s'", text)
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:start]})
+		}
+
+		expr := strings.TrimSpace(rest[start+2 : start+end])
+		eval, numeric, err := compileExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		if column, ok := strings.CutPrefix(expr, "subscriber."); ok {
+			t.columns = append(t.columns, normalizeDatasetColumn(column))
+		}
+		t.parts = append(t.parts, templatePart{source: "{{" + expr + "}}", eval: eval, numeric: numeric})
+		rest = rest[start+end+2:]
+	}
+	return t, nil
+}
+
+// compileExpression compiles one expression into its evaluation function
+func compileExpression(expr string) (templateFunc, bool, error) {
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return nil, false, fmt.Errorf("empty template expression")
+	}
+
+	name, args := fields[0], fields[1:]
+	if column, ok := strings.CutPrefix(name, "subscriber."); ok && column != "" {
+		column = normalizeDatasetColumn(column)
+		return func(ctx *templateContext) string { return ctx.subscriber(column) }, false, expectArgs(expr, args, 0)
+	}
+
+	switch name {
+	case "uuid":
+		return evalUUID, false, expectArgs(expr, args, 0)
+	case "worker":
+		return func(ctx *templateContext) string { return strconv.Itoa(ctx.worker) }, true, expectArgs(expr, args, 0)
+	case "now", "now.rfc3339":
+		return func(ctx *templateContext) string { return ctx.now.UTC().Format(time.RFC3339) }, false, expectArgs(expr, args, 0)
+	case "now.rfc3339nano":
+		return func(ctx *templateContext) string { return ctx.now.UTC().Format(time.RFC3339Nano) }, false, expectArgs(expr, args, 0)
+	case "now.unix":
+		return func(ctx *templateContext) string { return strconv.FormatInt(ctx.now.Unix(), 10) }, true, expectArgs(expr, args, 0)
+	case "now.unixMilli":
+		return func(ctx *templateContext) string { return strconv.FormatInt(ctx.now.UnixMilli(), 10) }, true, expectArgs(expr, args, 0)
+	case "randInt":
+		if len(args) != 2 {
+			return nil, false, fmt.Errorf("template '%s': expected randInt MIN MAX", expr)
+		}
+		low, errLow := strconv.ParseInt(args[0], 10, 64)
+		high, errHigh := strconv.ParseInt(args[1], 10, 64)
+		if errLow != nil || errHigh != nil || high < low {
+			return nil, false, fmt.Errorf("template '%s': MIN and MAX must be integers with MIN <= MAX", expr)
+		}
+		return func(ctx *templateContext) string {
+			return strconv.FormatInt(low+rand.Int64N(high-low+1), 10)
+		}, true, nil
+	case "seq":
+		if len(args) == 0 {
+			return func(ctx *templateContext) string { return strconv.FormatInt(ctx.seq, 10) }, true, nil
+		}
+		eval, err := compileCounter(expr, "", args)
+		return eval, true, err
+	case "supi":
+		eval, err := compileCounter(expr, "imsi-", args)
+		return eval, false, err
+	case "gpsi":
+		eval, err := compileCounter(expr, "msisdn-", args)
+		return eval, false, err
+	}
+
+	return nil, false, fmt.Errorf("unknown template expression '%s' (available: %s)", expr, strings.Join(templateExpressions, ", "))
+}
+
+// expectArgs reports arguments given to an expression that takes none
+func expectArgs(expr string, args []string, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("template '%s': unexpected arguments", expr)
+	}
+	return nil
+}
+
+// compileCounter compiles 'from N [step M]': N for the first request, N+M for the next.
+// The digits keep the width of N, so leading zeros of identities are preserved.
+func compileCounter(expr, prefix string, args []string) (templateFunc, error) {
+	if len(args) != 2 && len(args) != 4 || args[0] != "from" || len(args) == 4 && args[2] != "step" {
+		return nil, fmt.Errorf("template '%s': expected 'from N [step M]'", expr)
+	}
+
+	digits := strings.TrimPrefix(args[1], prefix)
+	from, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || from < 0 {
+		return nil, fmt.Errorf("template '%s': '%s' is not a non-negative integer", expr, args[1])
+	}
+	step := int64(1)
+	if len(args) == 4 {
+		if step, err = strconv.ParseInt(args[3], 10, 64); err != nil {
+			return nil, fmt.Errorf("template '%s': step '%s' is not an integer", expr, args[3])
+		}
+	}
+
+	format := fmt.Sprintf("%s%%0%dd", prefix, len(digits))
+	return func(ctx *templateContext) string {
+		return fmt.Sprintf(format, from+(ctx.seq-1)*step)
+	}, nil
+}
+
+// evalUUID generates a random version 4 UUID
+func evalUUID(ctx *templateContext) string {
+	var b [16]byte
+	high, low := rand.Uint64(), rand.Uint64()
+	for i := 0; i < 8; i++ {
+		b[i], b[8+i] = byte(high>>(8*i)), byte(low>>(8*i))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// Render evaluates the template for one request
+func (t *valueTemplate) Render(ctx *templateContext) string {
+	if len(t.parts) == 1 && t.parts[0].eval == nil {
+		return t.parts[0].literal
+	}
+
+	var sb strings.Builder
+	for _, part := range t.parts {
+		if part.eval == nil {
+			sb.WriteString(part.literal)
+		} else {
+			sb.WriteString(part.eval(ctx))
+		}
+	}
+	return sb.String()
+}
+
+// IsStatic checks if the template renders the same text for every request
+func (t *valueTemplate) IsStatic() bool {
+	for _, part := range t.parts {
+		if part.eval != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNumeric checks if the template is a single numeric expression, sent as a JSON number
+func (t *valueTemplate) IsNumeric() bool {
+	return len(t.parts) == 1 && t.parts[0].numeric
+}
+
+// String shows the template with its expressions
+func (t *valueTemplate) String() string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		if part.eval == nil {
+			sb.WriteString(part.literal)
+		} else {
+			sb.WriteString(part.source)
+		}
+	}
+	return sb.String()
+}
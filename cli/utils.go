@@ -2,75 +2,23 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/devuk0204/ctrlbench/types"
 )
 
+// ExtractServicePath returns the service base path parsed from the spec's server URL
 func ExtractServicePath(service types.ServiceMetadata) string {
-	if service.OpenAPISpec != nil && len(service.OpenAPISpec.Servers) > 0 {
-		serverURL := service.OpenAPISpec.Servers[0].Url
-
-		if strings.Contains(serverURL, "{") {
-			if idx := strings.Index(serverURL, "}"); idx != -1 && len(serverURL) > idx+1 {
-				path := serverURL[idx+1:]
-				if strings.HasPrefix(path, "/") {
-					return path
-				}
-				return "/" + path
-			}
-		}
-
-		if strings.Contains(serverURL, "://") {
-			parts := strings.Split(serverURL, "://")
-			if len(parts) > 1 {
-				hostAndPath := parts[1]
-				if idx := strings.Index(hostAndPath, "/"); idx != -1 {
-					return hostAndPath[idx:]
-				}
-			}
-		}
-	}
-
-	return ExtractServicePathFromAPIs(service)
+	return service.Server.BasePath
 }
 
-func ExtractServicePathFromAPIs(service types.ServiceMetadata) string {
-	if len(service.APIs) == 0 {
-		return "/"
-	}
-
-	var firstPath string
-	for _, api := range service.APIs {
-		if api.Path != "" {
-			firstPath = api.Path
-			break
-		}
-	}
-
-	if firstPath == "" {
+// FormatServicePath formats a service base path for display
+func FormatServicePath(servicePath string) string {
+	if servicePath == "" {
 		return "/"
 	}
-
-	segments := strings.Split(strings.Trim(firstPath, "/"), "/")
-	if len(segments) > 0 {
-		if len(segments) >= 2 && (segments[1] == "v1" || segments[1] == "v2") {
-			return "/" + strings.Join(segments[:2], "/")
-		}
-		return "/" + segments[0]
-	}
-
-	return "/"
-}
-
-func ExtractVersionFromPath(servicePath string) string {
-	segments := strings.Split(strings.Trim(servicePath, "/"), "/")
-	for _, segment := range segments {
-		if strings.HasPrefix(segment, "v") && len(segment) <= 3 {
-			return segment
-		}
-	}
-	return "v1"
+	return servicePath
 }
 
 // API 이름 관련 유틸리티 함수들
@@ -101,6 +49,67 @@ func CleanServiceName(serviceName string) string {
 	return serviceName
 }
 
+// Security 관련 유틸리티 함수들
+
+// ExtractScopeSets returns the OAuth2 scopes of each security alternative of an API.
+// Alternatives are OR'ed, a token for any one set authorizes the API.
+func ExtractScopeSets(security []types.SecurityRequirement) types.ScopeSets {
+	var sets types.ScopeSets
+	seen := make(map[string]bool)
+
+	for _, requirement := range security {
+		if requirement.Type != "oauth2" {
+			continue
+		}
+		key := strings.Join(requirement.Scopes, " ")
+		if !seen[key] {
+			sets = append(sets, requirement.Scopes)
+			seen[key] = true
+		}
+	}
+
+	return sets
+}
+
+// SelectScopes picks the scope set tokens are requested for: the operation-level
+// alternative (most scopes, e.g. nudm-sdm nudm-sdm:nssai:read) or the service-level one (fewest)
+func SelectScopes(sets [][]string, alternative string) []string {
+	var selected []string
+	for i, set := range sets {
+		switch {
+		case i == 0:
+		case alternative == types.AuthScopesService && len(set) < len(selected):
+		case alternative != types.AuthScopesService && len(set) > len(selected):
+		default:
+			continue
+		}
+		selected = set
+	}
+	return selected
+}
+
+// FormatScopeSets shows scope sets as alternatives, e.g. [nudm-sdm] or [nudm-sdm nudm-sdm:nssai:read]
+func FormatScopeSets(sets [][]string) string {
+	formatted := make([]string, len(sets))
+	for i, set := range sets {
+		formatted[i] = fmt.Sprintf("%v", set)
+	}
+	return strings.Join(formatted, " or ")
+}
+
+// IsAnonymousAccessAllowed checks if any security alternative allows requests without a token
+func IsAnonymousAccessAllowed(security []types.SecurityRequirement) bool {
+	if len(security) == 0 {
+		return true
+	}
+	for _, requirement := range security {
+		if requirement.Scheme == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // NF 관련 유틸리티 함수들
 func GroupServicesByNF(services map[string]types.ServiceMetadata) map[string][]types.ServiceMetadata {
 	nfServices := make(map[string][]types.ServiceMetadata)
@@ -111,9 +120,42 @@ func GroupServicesByNF(services map[string]types.ServiceMetadata) map[string][]t
 		}
 		nfServices[nf] = append(nfServices[nf], service)
 	}
+	for _, serviceList := range nfServices {
+		sort.Slice(serviceList, func(i, j int) bool { return serviceList[i].SpecFile < serviceList[j].SpecFile })
+	}
 	return nfServices
 }
 
+// FindOperation - Find the OpenAPI operation of an API by its path and method
+func FindOperation(service types.ServiceMetadata, api types.APIMetadata) *types.Operation {
+	if service.OpenAPISpec == nil || len(api.Methods) == 0 {
+		return nil
+	}
+
+	pathItem, exists := service.OpenAPISpec.Paths[api.Path]
+	if !exists {
+		return nil
+	}
+
+	switch api.Methods[0] {
+	case "GET":
+		return pathItem.Get
+	case "POST":
+		return pathItem.Post
+	case "PUT":
+		return pathItem.Put
+	case "DELETE":
+		return pathItem.Delete
+	case "PATCH":
+		return pathItem.Patch
+	case "HEAD":
+		return pathItem.Head
+	case "OPTIONS":
+		return pathItem.Options
+	}
+	return nil
+}
+
 // IsPathParameter - Check if parameter is a path parameter
 func IsPathParameter(paramName, path string) bool {
 	return strings.Contains(path, "{"+paramName+"}")
@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// ValidateAPICatalog checks every API of apiList (or of one NF) against the configuration
+// and reports which ones can run and which values are missing or invalid for the others
+func ValidateAPICatalog(apiList types.APIList, config *types.ConfigurationFile, configFile, nfFilter string) (*types.ValidationReport, error) {
+	report := &types.ValidationReport{Config: configFile}
+
+	for _, nf := range getSortedKeys(apiList) {
+		if nfFilter != "" && !strings.EqualFold(nf, nfFilter) {
+			continue
+		}
+
+		// Runs of disabled NFs are refused, an invalid policy blocks every API of the NF
+		policy, policyErr := ResolveNFPolicy(config, nf)
+
+		for _, serviceName := range getSortedKeys(apiList[nf]) {
+			service := apiList[nf][serviceName]
+			for _, apiName := range getSortedKeys(service.APIs) {
+				entry := service.APIs[apiName]
+				result := types.APIValidation{
+					NF:       nf,
+					Service:  serviceName,
+					API:      apiName,
+					Method:   entry.Method,
+					Runnable: true,
+				}
+
+				if policyErr == nil && !policy.Enabled {
+					result.Runnable, result.Disabled = false, true
+					report.Disabled++
+					report.APIs = append(report.APIs, result)
+					continue
+				}
+
+				problems := validateAPIConfiguration(&entry, APIRequestBodyKey(nf, apiName), config.UserInputs)
+				if policyErr != nil {
+					problems = append([]types.ValidationProblem{{
+						Severity: types.LintError,
+						Kind:     types.ValidationInvalid,
+						Target:   "policy",
+						Name:     nf,
+						Config:   "nf_settings." + nf,
+						Message:  policyErr.Error(),
+					}}, problems...)
+				}
+				result.Problems = problems
+				for _, problem := range problems {
+					if problem.Severity == types.LintError {
+						result.Runnable = false
+					} else {
+						report.Warnings++
+					}
+				}
+
+				if result.Runnable {
+					report.Runnable++
+				} else {
+					report.NotRunnable++
+				}
+				report.APIs = append(report.APIs, result)
+			}
+		}
+	}
+
+	if len(report.APIs) == 0 && nfFilter != "" {
+		return nil, fmt.Errorf("NF '%s' not found in api_list.yaml", nfFilter)
+	}
+	return report, nil
+}
+
+// validateAPIConfiguration checks the parameters and request body of one API
+// with the same rules PrepareAPIExecution applies, collecting every problem
+func validateAPIConfiguration(entry *types.APIListEntry, apiRef string, userInputs types.UserInputSection) []types.ValidationProblem {
+	var problems []types.ValidationProblem
+
+	for _, p := range entry.Parameters {
+		if p.Name == "" {
+			continue
+		}
+
+		location := parameterConfigLocation(p.Name, userInputs)
+		value := getParameterValue(p.Name, userInputs.CommonParameters, userInputs.APISpecificParameters)
+		if IsEmptyValue(value) {
+			if p.Required {
+				problems = append(problems, types.ValidationProblem{
+					Severity: types.LintError,
+					Kind:     types.ValidationMissing,
+					Target:   "parameter",
+					Name:     p.Name,
+					Config:   location,
+					Message:  fmt.Sprintf("required %s parameter has no value", p.In),
+				})
+			}
+			continue
+		}
+
+		if p.ContentType != "" {
+			continue // Serialized as JSON, any value is encoded
+		}
+		constraint := types.ValueConstraint{Type: p.Type, Enum: p.Enum, Extensible: p.Extensible}
+		if problem, ok := checkConfiguredValue(value, constraint, false); !ok {
+			problem.Target, problem.Name, problem.Config = "parameter", p.Name, location
+			problems = append(problems, problem)
+		}
+	}
+
+	body := entry.RequestBodySchema
+	composed, missing, err := composeRequestBody(entry, apiRef, userInputs)
+	for _, fieldName := range missing {
+		problems = append(problems, types.ValidationProblem{
+			Severity: types.LintError,
+			Kind:     types.ValidationMissing,
+			Target:   "body",
+			Name:     body.SchemaName + "." + fieldName,
+			Config:   bodyFieldConfigLocation(body.SchemaName, fieldName, userInputs),
+			Message:  "required body field has no value and no generated default",
+		})
+	}
+	if err != nil {
+		config := bodyConfigSection(body.SchemaName, userInputs)
+		if apiKey, _ := lookupAPIRequestBody(userInputs.APISpecificRequestBodies, apiRef, entry.Key); apiKey != "" {
+			config = "api_specific_request_bodies." + apiKey
+		}
+		problems = append(problems, types.ValidationProblem{
+			Severity: types.LintError,
+			Kind:     types.ValidationInvalid,
+			Target:   "body",
+			Name:     body.SchemaName,
+			Config:   config,
+			Message:  err.Error(),
+		})
+	}
+
+	if _, _, _, contentErr := resolveRequestContent(entry, apiRef, userInputs, composed); contentErr != nil && err == nil {
+		apiKey, _ := lookupAPIRequestBody(userInputs.APISpecificRequestBodies, apiRef, entry.Key)
+		if apiKey == "" {
+			apiKey = apiRef
+		}
+		problems = append(problems, types.ValidationProblem{
+			Severity: types.LintError,
+			Kind:     types.ValidationInvalid,
+			Target:   "body",
+			Name:     body.SchemaName,
+			Config:   "api_specific_request_bodies." + apiKey,
+			Message:  contentErr.Error(),
+		})
+	}
+
+	for _, fieldName := range configuredBodyFields(body.SchemaName, userInputs.CommonRequestBodies, userInputs.APISpecificRequestBodies) {
+		constraint, known := body.Fields[fieldName]
+		value := getBodyFieldValue(fieldName, body.SchemaName, userInputs.CommonRequestBodies, userInputs.APISpecificRequestBodies)
+		if !known || IsEmptyValue(value) {
+			continue
+		}
+		if problem, ok := checkConfiguredValue(value, constraint, true); !ok {
+			problem.Target = "body"
+			problem.Name = body.SchemaName + "." + fieldName
+			problem.Config = bodyFieldConfigLocation(body.SchemaName, fieldName, userInputs)
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// checkConfiguredValue checks a value against a resolved type and enumeration.
+// Body values are strict JSON types, parameters are sent as text so numeric
+// or boolean strings are accepted for them. Templates are only known per request.
+func checkConfiguredValue(value interface{}, constraint types.ValueConstraint, strict bool) (types.ValidationProblem, bool) {
+	if text, ok := value.(string); ok && hasTemplate(text) {
+		return types.ValidationProblem{}, true
+	}
+	if constraint.Type != "" && !valueHasType(value, constraint.Type, strict) {
+		return types.ValidationProblem{
+			Severity: types.LintError,
+			Kind:     types.ValidationType,
+			Message:  fmt.Sprintf("expected %s, got %s", constraint.Type, describeValue(value)),
+		}, false
+	}
+
+	if len(constraint.Enum) == 0 || isCompositeValue(value) {
+		return types.ValidationProblem{}, true
+	}
+	for _, allowed := range constraint.Enum {
+		if fmt.Sprintf("%v", allowed) == fmt.Sprintf("%v", value) {
+			return types.ValidationProblem{}, true
+		}
+	}
+
+	problem := types.ValidationProblem{
+		Severity: types.LintError,
+		Kind:     types.ValidationEnum,
+		Message:  fmt.Sprintf("%v is not one of %s", value, formatEnum(constraint.Enum)),
+	}
+	if constraint.Extensible {
+		// Extensible enumerations accept other values, but a typo is more likely
+		problem.Severity = types.LintWarning
+		problem.Message = fmt.Sprintf("%v is not one of the values defined by the specification %s", value, formatEnum(constraint.Enum))
+	}
+	return problem, false
+}
+
+// valueHasType checks if a configuration value can be used as a value of schemaType
+func valueHasType(value interface{}, schemaType string, strict bool) bool {
+	text, isString := value.(string)
+
+	switch schemaType {
+	case "integer":
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		}
+		if _, err := strconv.ParseInt(text, 10, 64); isString && !strict && err == nil {
+			return true
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		if _, err := strconv.ParseFloat(text, 64); isString && !strict && err == nil {
+			return true
+		}
+		return false
+	case "boolean":
+		if _, ok := value.(bool); ok {
+			return true
+		}
+		return isString && !strict && (text == "true" || text == "false")
+	case "string":
+		return isString || (!strict && !isCompositeValue(value))
+	case "array":
+		_, ok := value.([]interface{})
+		return ok || (!strict && !isCompositeValue(value)) // Parameters accept comma separated text
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok || (!strict && isString) // Parameters accept JSON text
+	}
+	return true
+}
+
+// isCompositeValue checks if a value is a list or an object
+func isCompositeValue(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// describeValue describes a configuration value for error messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case int, int64, uint64:
+		return fmt.Sprintf("integer %v", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// formatEnum formats enumerated values, abbreviating long lists
+func formatEnum(enum []interface{}) string {
+	const maxShown = 8
+
+	values := make([]string, 0, maxShown+1)
+	for i, value := range enum {
+		if i == maxShown {
+			values = append(values, fmt.Sprintf("... %d more", len(enum)-maxShown))
+			break
+		}
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// parameterConfigLocation returns where a parameter value is read from in configuration.yaml
+func parameterConfigLocation(name string, userInputs types.UserInputSection) string {
+	if _, exists := userInputs.CommonParameters[name]; exists {
+		return "common_parameters." + name + ".value"
+	}
+	if _, exists := userInputs.APISpecificParameters[name]; exists {
+		return "api_specific_parameters." + name + ".value"
+	}
+	return "api_specific_parameters." + name + " (not in configuration, rebuild it with -b)"
+}
+
+// bodyFieldConfigLocation returns where a body field value is read from in configuration.yaml
+func bodyFieldConfigLocation(schemaName, fieldName string, userInputs types.UserInputSection) string {
+	section := bodyConfigSection(schemaName, userInputs)
+	if section == "" {
+		return "api_specific_request_bodies." + schemaName + " (not in configuration, rebuild it with -b)"
+	}
+	return section + ".properties." + fieldName + ".value"
+}
+
+// bodyConfigSection returns the configuration entry of a request body schema, empty if there is none
+func bodyConfigSection(schemaName string, userInputs types.UserInputSection) string {
+	if _, exists := userInputs.CommonRequestBodies[schemaName]; exists {
+		return "common_request_bodies." + schemaName
+	}
+	if _, exists := userInputs.APISpecificRequestBodies[schemaName]; exists {
+		return "api_specific_request_bodies." + schemaName
+	}
+	return ""
+}
+
+// PrintValidationReport prints a validation report in text or json format
+func PrintValidationReport(report *types.ValidationReport, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "text", "":
+		printValidationText(report)
+		return nil
+	default:
+		return fmt.Errorf("unknown validate output format '%s' (text, json)", format)
+	}
+}
+
+// printValidationText prints the runnable matrix, the problems of each API and
+// the configuration values to fill, most blocking first
+func printValidationText(report *types.ValidationReport) {
+	fmt.Printf("🔎 Configuration Validation Report (%s)\n", report.Config)
+	fmt.Println(strings.Repeat("=", 50))
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NF\tSERVICE\tAPI\tMETHOD\tSTATUS")
+	for _, api := range report.APIs {
+		status := "✅ runnable"
+		if api.Disabled {
+			status = "⏭️  disabled"
+		} else if !api.Runnable {
+			status = fmt.Sprintf("❌ %s", countProblems(api.Problems, types.LintError))
+		} else if len(api.Problems) > 0 {
+			status = fmt.Sprintf("⚠️  runnable, %s", countProblems(api.Problems, types.LintWarning))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", api.NF, api.Service, api.API, api.Method, status)
+	}
+	table.Flush()
+
+	blocked := make(map[string][]string) // config location → APIs it blocks
+	for _, api := range report.APIs {
+		if len(api.Problems) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n📄 %s %s %s [%s]\n", api.NF, api.Service, api.API, api.Method)
+		for _, problem := range api.Problems {
+			icon := "⚠️ "
+			if problem.Severity == types.LintError {
+				icon = "❌"
+				blocked[problem.Config] = append(blocked[problem.Config], api.NF+"."+api.API)
+			}
+			fmt.Printf("    %s [%s] %s %s: %s\n", icon, problem.Kind, problem.Target, problem.Name, problem.Message)
+			fmt.Printf("       → %s\n", problem.Config)
+		}
+	}
+
+	if len(blocked) > 0 {
+		locations := getSortedKeys(blocked)
+		sort.SliceStable(locations, func(i, j int) bool {
+			return len(blocked[locations[i]]) > len(blocked[locations[j]])
+		})
+
+		fmt.Printf("\n📋 Values to fix in %s:\n", report.Config)
+		for _, location := range locations {
+			fmt.Printf("    %-70s blocks %d API(s)\n", location, len(blocked[location]))
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Printf("APIs: %d, Runnable: %d, Not runnable: %d, Disabled: %d, Warnings: %d\n",
+		len(report.APIs), report.Runnable, report.NotRunnable, report.Disabled, report.Warnings)
+}
+
+// countProblems formats the number of problems of a severity
+func countProblems(problems []types.ValidationProblem, severity string) string {
+	count := 0
+	for _, problem := range problems {
+		if problem.Severity == severity {
+			count++
+		}
+	}
+	if severity == types.LintError {
+		return fmt.Sprintf("%d error(s)", count)
+	}
+	return fmt.Sprintf("%d warning(s)", count)
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,12 +20,36 @@ var (
 	helpFlag        = flag.Bool("h", false, "Show help information")
 	apiFlag         = flag.String("a", "", "API method name")
 	targetNFFlag    = flag.String("t", "", "Target NF name")
+	serviceFlag     = flag.String("s", "", "Service name (to disambiguate APIs with the same name)")
 	iterationsFlag  = flag.Int("i", 1, "Number of iterations")
 	buildConfigFlag = flag.Bool("b", false, "Build configuration file")
+	freshConfigFlag = flag.Bool("f", false, "Build configuration file from scratch instead of keeping entered values")
+	releaseFlag     = flag.String("r", "", "3GPP release bundle, e.g. rel-17 or AMF=rel-16,SMF=rel-17")
+	configFlag      = flag.String("c", cli.ConfigurationFileName, "Configuration file")
+	profileFlag     = flag.String("profile", "", "Configuration profile (default $CTRLBENCH_PROFILE)")
+	forceFlag       = flag.Bool("force", false, "Send requests even if the pre-flight check fails (negative tests)")
+	refreshFlag     = flag.Bool("refresh-discovery", false, "Query the NRF even when a cached discovery result is valid")
+	setFlags        setFlag
 )
 
+func init() {
+	flag.Var(&setFlags, "set", "Override a configuration value, path=value (repeatable)")
+}
+
+// setFlag collects repeated -set path=value flags
+type setFlag []string
+
+func (s *setFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *setFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // runAPIExecution executes API calls using api_list.yaml and configuration.yaml
-func runAPIExecution(targetNF, apiName string, iterations int) {
+func runAPIExecution(targetNF, serviceName, apiName string, iterations int) {
 	fmt.Printf("   Starting API execution for %s.%s\n", targetNF, apiName)
 	fmt.Printf("   Iterations: %d\n\n", iterations)
 
@@ -31,7 +57,7 @@ func runAPIExecution(targetNF, apiName string, iterations int) {
 	executor := cli.NewAPIExecutor(30 * time.Second)
 
 	// Prepare execution info using api_list.yaml
-	execInfo, err := executor.ExecuteAPI(targetNF, apiName)
+	execInfo, err := executor.ExecuteAPI(targetNF, serviceName, apiName)
 	if err != nil {
 		log.Printf("  Failed to prepare API execution: %v", err)
 		os.Exit(1)
@@ -39,17 +65,34 @@ func runAPIExecution(targetNF, apiName string, iterations int) {
 
 	fmt.Printf("  Execution Details:\n")
 	fmt.Printf("   NF: %s\n", execInfo.NF)
+	fmt.Printf("   Service: %s\n", execInfo.Service)
 	fmt.Printf("   API: %s\n", execInfo.APIName)
 	fmt.Printf("   Method: %s\n", execInfo.Method)
 	fmt.Printf("   Path: %s\n", execInfo.Path)
 	fmt.Printf("   Discovered URL: %s\n", execInfo.DiscoveredURL)
-	fmt.Printf("   Parameters: %v\n", execInfo.Parameters)
+	fmt.Printf("   Parameters:\n")
+	for _, param := range execInfo.Parameters {
+		if cli.IsEmptyValue(param.Value) {
+			continue
+		}
+		fmt.Printf("     [%s] %s: %v\n", param.In, param.Name, param.Value)
+	}
+	if execInfo.ContentType != "" {
+		fmt.Printf("   Content Type: %s\n", execInfo.ContentType)
+	}
+	for _, part := range execInfo.Parts {
+		fmt.Printf("     [part] %s: %s, %d bytes\n", part.ContentID, part.ContentType, len(part.Data))
+	}
 	if execInfo.RequestBody != nil {
 		bodyBytes, _ := json.Marshal(execInfo.RequestBody)
 		fmt.Printf("   Request Body: %s\n", string(bodyBytes))
 	}
 	fmt.Println()
 
+	if !preflightCheck(executor, execInfo) {
+		os.Exit(1)
+	}
+
 	var result types.BenchmarkResult
 	result.TotalRequests = iterations
 	result.MinTime = time.Hour
@@ -99,41 +142,317 @@ func runAPIExecution(targetNF, apiName string, iterations int) {
 	fmt.Printf("Minimum: %v\n", result.MinTime)
 	fmt.Printf("Maximum: %v\n", result.MaxTime)
 	fmt.Printf("Total Duration: %v\n", totalElapsed)
+
+	printInstanceStats(executor.InstanceStats())
 }
 
-func main() {
-	flag.Parse()
+// printInstanceStats reports the results per discovered NF instance. Instances with
+// failures or an average response time over twice the median instance are flagged.
+func printInstanceStats(stats []types.InstanceStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	averages := make([]time.Duration, 0, len(stats))
+	for _, s := range stats {
+		averages = append(averages, s.TotalTime/time.Duration(s.Requests))
+	}
+	sorted := slices.Clone(averages)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	fmt.Println()
+	fmt.Printf("Per NF Instance:\n")
+	for i, s := range stats {
+		avg := averages[i]
+		mark := "✅"
+		slow := avg > 2*median && avg-median > time.Millisecond // Ignores jitter of fast local replicas
+		if s.FailureCount > 0 || slow {
+			mark = "⚠️ "
+		}
+		fmt.Printf("%s %s (%s)\n", mark, s.InstanceID, s.URL)
+		fmt.Printf("   Requests: %d, Failed: %d (%.2f%%)\n", s.Requests, s.FailureCount, float64(s.FailureCount)/float64(s.Requests)*100)
+		fmt.Printf("   Average: %v, Minimum: %v, Maximum: %v\n", avg, s.MinTime, s.MaxTime)
+		if lastError := s.LastError; lastError != "" {
+			if len(lastError) > 120 {
+				lastError = lastError[:120] + "..."
+			}
+			fmt.Printf("   Last error: %s\n", lastError)
+		}
+	}
+}
+
+// preflightCheck checks the first request against the schema of the operation in the
+// specifications and reports whether the run may start
+func preflightCheck(executor *cli.APIExecutor, execInfo *types.APIExecutionInfo) bool {
+	var specs fs.FS
+	for _, candidate := range []fs.FS{os.DirFS("./openapi"), embeddedSpecs()} {
+		if candidate == nil || execInfo.SpecFile == "" {
+			continue
+		}
+		if _, err := fs.Stat(candidate, execInfo.SpecFile); err == nil {
+			specs = candidate
+			break
+		}
+	}
+	if specs == nil {
+		fmt.Printf("⚠️  Pre-flight check skipped: specification of %s not found\n", execInfo.APIName)
+		return true
+	}
+
+	params, body, err := executor.SampleRequest(execInfo)
+	if err != nil {
+		fmt.Printf("❌ Pre-flight check failed: %v\n", err)
+		return false
+	}
+	problems, err := parser.NewRequestChecker(specs).CheckRequest(execInfo.SpecFile, execInfo.Path, execInfo.Method, params, body, execInfo.ContentType)
+	if err != nil {
+		fmt.Printf("⚠️  Pre-flight check skipped: %v\n", err)
+		return true
+	}
+	if len(problems) == 0 {
+		fmt.Printf("✅ Pre-flight check passed against %s\n\n", execInfo.SpecFile)
+		return true
+	}
 
-	var services map[string]types.ServiceMetadata
+	fmt.Printf("❌ Pre-flight check found %d problem(s) against %s:\n", len(problems), execInfo.SpecFile)
+	for _, problem := range problems {
+		fmt.Printf("    [%s] %s %s: %s\n", problem.Kind, problem.Target, problem.Name, problem.Message)
+	}
+	if *forceFlag {
+		fmt.Printf("⚠️  Sending the requests anyway (-force)\n\n")
+		return true
+	}
+	fmt.Println("   Fix the configuration, or run with -force to send the requests anyway")
+	return false
+}
+
+// loadServices parses all release bundles and selects one release per NF.
+// The bundles embedded in the binary are used when the directory holds none.
+// Parsed bundles are cached in the spec index until a spec file changes.
+func loadServices(openapiDir string) (map[string]types.ServiceMetadata, error) {
+	var bundles map[string]map[string]types.ServiceMetadata
 
-	openapiDir := "./openapi"
 	if _, err := os.Stat(openapiDir); err == nil {
-		services, err = parser.ParseOpenAPIDir(openapiDir)
+		bundles, err = parser.ParseOpenAPIBundlesCached(os.DirFS(openapiDir), ".", parser.DefaultIndexPath)
 		if err != nil {
-			log.Printf("   Failed to parse OpenAPI dir: %v", err)
-			os.Exit(1)
+			return nil, err
 		}
-	} else {
-		log.Printf("   OpenAPI dir '%s' not found, please create it and add your OpenAPI YAML files", openapiDir)
 	}
 
-	if *helpFlag {
-		if flag.NArg() == 0 {
-			cli.PrintUsage()
-		} else if strings.EqualFold(flag.Arg(0), "all") {
-			cli.ShowHelp(services, "")
-		} else {
-			cli.ShowHelp(services, flag.Arg(0))
+	if len(bundles) == 0 {
+		specs := embeddedSpecs()
+		if specs == nil {
+			log.Printf("   OpenAPI dir '%s' not found, please create it and add your OpenAPI YAML or JSON files", openapiDir)
+			return nil, nil
 		}
+
+		fmt.Printf("📦 Using OpenAPI specifications embedded in the binary\n")
+		var err error
+		bundles, err = parser.ParseOpenAPIBundlesCached(specs, ".", parser.DefaultIndexPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	selection, err := cli.LoadReleaseSelection(*releaseFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.SelectReleases(bundles, selection)
+}
+
+// checkSubcommandFlags rejects flags before a subcommand that it does not take. The
+// configuration flags -c, -profile and -set are the defaults of validate and config.
+func checkSubcommandFlags(command string) error {
+	var err error
+	flag.Visit(func(f *flag.Flag) {
+		shared := f.Name == "c" || f.Name == "profile" || f.Name == "set"
+		if err == nil && (command == "lint" || !shared) {
+			err = fmt.Errorf("-%s is not an option of '%s', place the flags of a subcommand after its name", f.Name, command)
+		}
+	})
+	return err
+}
+
+// runLint lints the openapi directory and returns the process exit code
+func runLint(args []string) int {
+	lintFlags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := lintFlags.String("format", "text", "Output format (text, json)")
+	dir := lintFlags.String("dir", "./openapi", "OpenAPI directory to lint")
+	lintFlags.Parse(args)
+
+	report, err := parser.LintOpenAPIDir(*dir)
+	if err != nil {
+		log.Printf("   Failed to lint OpenAPI dir: %v", err)
+		return 2
+	}
+
+	if err := cli.PrintLintReport(report, *format); err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runValidate checks configuration.yaml against every API of api_list.yaml and returns the process exit code
+func runValidate(args []string) int {
+	validateFlags := flag.NewFlagSet("validate", flag.ExitOnError)
+	format := validateFlags.String("format", "text", "Output format (text, json)")
+	configFile := validateFlags.String("config", *configFlag, "Configuration file to validate")
+	validateFlags.StringVar(configFile, "c", *configFlag, "Configuration file to validate")
+	profile := validateFlags.String("profile", *profileFlag, "Configuration profile (default $CTRLBENCH_PROFILE)")
+	sets := append(setFlag{}, setFlags...)
+	validateFlags.Var(&sets, "set", "Override a configuration value, path=value (repeatable)")
+
+	// validate [NF] [flags] as well as validate [flags] [NF]
+	nfFilter := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		nfFilter, args = args[0], args[1:]
+	}
+	validateFlags.Parse(args)
+	if nfFilter == "" {
+		nfFilter = validateFlags.Arg(0)
+	}
+
+	apiList, err := cli.LoadAPIList()
+	if err != nil {
+		log.Printf("   %v (build it with -b)", err)
+		return 2
+	}
+
+	if err := cli.SetConfigurationSource(*configFile, *profile, sets); err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+
+	config, err := cli.LoadEffectiveConfiguration()
+	if err != nil {
+		log.Printf("   Invalid configuration:\n%v", err)
+		return 2
+	}
+
+	report, err := cli.ValidateAPICatalog(apiList, config, *configFile, strings.ToUpper(nfFilter))
+	if err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+
+	if err := cli.PrintValidationReport(report, *format); err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+
+	if report.NotRunnable > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runConfig shows the configured values of the selected profile and where they came from,
+// with -effective including environment variables and -set flags, and returns the process exit code
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "show" {
+		log.Printf("   Usage: ctrlbench config show [--effective] [-c FILE] [-profile NAME] [-set path=value] [-format json]")
+		return 2
+	}
+
+	configFlags := flag.NewFlagSet("config show", flag.ExitOnError)
+	effective := configFlags.Bool("effective", false, "Include environment variables and -set overrides")
+	format := configFlags.String("format", "text", "Output format (text, json)")
+	configFile := configFlags.String("c", *configFlag, "Configuration file")
+	profile := configFlags.String("profile", *profileFlag, "Configuration profile (default $CTRLBENCH_PROFILE)")
+	sets := append(setFlag{}, setFlags...)
+	configFlags.Var(&sets, "set", "Override a configuration value, path=value (repeatable)")
+	configFlags.Parse(args[1:])
+
+	if err := cli.SetConfigurationSource(*configFile, *profile, sets); err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+
+	var overrides []types.ConfigOverride
+	if *effective {
+		overrides = cli.ConfigurationOverrides()
+	}
+
+	values, err := cli.LoadConfigurationValues(cli.ConfigurationPath(), cli.ConfigurationProfile(), overrides...)
+	if err != nil {
+		log.Printf("   Invalid configuration:\n%v", err)
+		return 2
+	}
+
+	if err := cli.PrintConfigValues(values, *format); err != nil {
+		log.Printf("   %v", err)
+		return 2
+	}
+	return 0
+}
+
+func main() {
+	// NF/service mapping overrides apply to every command that reads specifications
+	mapping, err := cli.LoadNFMapping()
+	if err != nil {
+		log.Printf("   %v", err)
+		os.Exit(1)
+	}
+	parser.SetNFMapping(mapping)
+
+	flag.Parse()
+
+	// Subcommands, also after the configuration flags: ctrlbench -c lab.yaml config show
+	if command := flag.Arg(0); command == "lint" || command == "validate" || command == "config" {
+		if err := checkSubcommandFlags(command); err != nil {
+			log.Printf("   %v", err)
+			os.Exit(2)
+		}
+		switch command {
+		case "lint":
+			os.Exit(runLint(flag.Args()[1:]))
+		case "validate":
+			os.Exit(runValidate(flag.Args()[1:]))
+		default:
+			os.Exit(runConfig(flag.Args()[1:]))
+		}
+	}
+
+	if err := cli.SetConfigurationSource(*configFlag, *profileFlag, setFlags); err != nil {
+		log.Printf("   %v", err)
+		os.Exit(1)
+	}
+
+	// API execution only needs api_list.yaml, the specifications are parsed for -h and -b
+	if *helpFlag && flag.NArg() == 0 {
+		cli.PrintUsage()
 		return
 	}
 
-	if *buildConfigFlag {
+	if *helpFlag || *buildConfigFlag {
+		services, err := loadServices("./openapi")
+		if err != nil {
+			log.Printf("   Failed to parse OpenAPI dir: %v", err)
+			os.Exit(1)
+		}
+
+		if *helpFlag {
+			if strings.EqualFold(flag.Arg(0), "all") {
+				cli.ShowHelp(services, "")
+			} else {
+				cli.ShowHelp(services, flag.Arg(0))
+			}
+			return
+		}
+
 		nfFilter := ""
 		if flag.NArg() > 0 {
 			nfFilter = flag.Arg(0)
 		}
-		err := cli.BuildConfiguration(services, nfFilter)
+		err = cli.BuildConfiguration(services, nfFilter, *freshConfigFlag)
 		if err != nil {
 			log.Printf("  Failed to build configuration: %v", err)
 			os.Exit(1)
@@ -143,8 +462,9 @@ func main() {
 
 	// Handle API execution with api_list.yaml
 	if *targetNFFlag != "" && *apiFlag != "" {
+		cli.SetDiscoveryRefresh(*refreshFlag)
 		var targetNF = strings.ToUpper(*targetNFFlag)
-		runAPIExecution(targetNF, *apiFlag, *iterationsFlag)
+		runAPIExecution(targetNF, *serviceFlag, *apiFlag, *iterationsFlag)
 		return
 	}
 
@@ -0,0 +1,762 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// maxExampleDepth bounds schema nesting so deeply recursive types stay small
+const maxExampleDepth = 16
+
+// schemaResolver resolves $refs across the specification files of one bundle directory
+type schemaResolver struct {
+	fsys      fs.FS
+	documents map[string]interface{}
+	loadErrs  map[string]error
+}
+
+// newSchemaResolver creates a resolver over fsys
+func newSchemaResolver(fsys fs.FS) *schemaResolver {
+	return &schemaResolver{
+		fsys:      fsys,
+		documents: make(map[string]interface{}),
+		loadErrs:  make(map[string]error),
+	}
+}
+
+// document loads and caches a specification as generic maps
+func (r *schemaResolver) document(filePath string) (interface{}, error) {
+	if doc, exists := r.documents[filePath]; exists {
+		return doc, nil
+	}
+	if err, exists := r.loadErrs[filePath]; exists {
+		return nil, err
+	}
+
+	var doc interface{}
+	data, err := fs.ReadFile(r.fsys, filePath)
+	if err == nil {
+		node, decodeErr := decodeDocument(filePath, data)
+		if err = decodeErr; err == nil {
+			err = node.Decode(&doc)
+		}
+	}
+	if err != nil {
+		r.loadErrs[filePath] = err
+		return nil, err
+	}
+
+	r.documents[filePath] = doc
+	return doc, nil
+}
+
+// resolve follows a $ref found in filePath and returns the target and the file it lives in
+func (r *schemaResolver) resolve(filePath, ref string) (interface{}, string, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	target := filePath
+	if file != "" {
+		target = path.Join(path.Dir(filePath), file)
+	}
+
+	doc, err := r.document(target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var tokens []string
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token != "" {
+			tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+	}
+
+	node, ok := lookupPath(doc, tokens...)
+	if !ok {
+		return nil, "", fmt.Errorf("'%s' not found in %s", pointer, target)
+	}
+	return node, target, nil
+}
+
+// lookupPath walks object keys of a generic document
+func lookupPath(node interface{}, keys ...string) (interface{}, bool) {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// bodyGenerator synthesizes schema-valid example values from resolved schemas
+type bodyGenerator struct {
+	resolver *schemaResolver
+	visiting map[string]bool // $refs on the current path, to stop at recursive schemas
+}
+
+// newBodyGenerator creates a generator resolving $refs in fsys
+func newBodyGenerator(fsys fs.FS) *bodyGenerator {
+	return &bodyGenerator{resolver: newSchemaResolver(fsys), visiting: make(map[string]bool)}
+}
+
+// synthesizeRequestBodies attaches a generated request body to every API of service
+// whose operation in specPath declares a JSON request body
+func (g *bodyGenerator) synthesizeRequestBodies(specPath string, service types.ServiceMetadata) {
+	doc, err := g.resolver.document(specPath)
+	if err != nil {
+		return
+	}
+
+	for name, api := range service.APIs {
+		if len(api.Methods) == 0 {
+			continue
+		}
+
+		requestBody, ok := lookupPath(doc, "paths", api.Path, strings.ToLower(api.Methods[0]), "requestBody")
+		if !ok {
+			continue
+		}
+
+		file := specPath
+		if m, isMap := requestBody.(map[string]interface{}); isMap {
+			if ref, isRef := m["$ref"].(string); isRef {
+				resolved, resolvedFile, err := g.resolver.resolve(file, ref)
+				if err != nil {
+					continue
+				}
+				requestBody, file = resolved, resolvedFile
+			}
+		}
+
+		content, _ := lookupPath(requestBody, "content")
+		contentMap, _ := content.(map[string]interface{})
+		schemaPath := jsonSchemaPath(contentMap)
+		if schemaPath == nil {
+			continue
+		}
+
+		schema, ok := lookupPath(contentMap, schemaPath...)
+		if !ok {
+			continue
+		}
+
+		if example, ok := g.generate(schema, file, 0); ok {
+			if strings.Contains(schemaPath[0], "json-patch") {
+				example = patchExample(example)
+			}
+			if schemaPath[0] == "multipart/related" {
+				assignContentIDs(example, binaryPartNames(api.RequestParts))
+			}
+			api.RequestBodyExample = example
+			service.APIs[name] = api
+		}
+	}
+}
+
+// patchExample turns the generated items of a JSON Patch body into valid operations:
+// a JSON pointer path, and a value for the operations that need one
+func patchExample(example interface{}) interface{} {
+	items, ok := example.([]interface{})
+	if !ok {
+		return example
+	}
+	for _, item := range items {
+		operation, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		op, _ := operation["op"].(string)
+		if op == "" {
+			op = "replace"
+			operation["op"] = op
+		}
+		for _, key := range []string{"path", "from"} {
+			if path, exists := operation[key].(string); exists && !strings.HasPrefix(path, "/") {
+				operation[key] = "/"
+			}
+		}
+		if _, exists := operation["path"]; !exists {
+			operation["path"] = "/"
+		}
+		if _, exists := operation["value"]; !exists && (op == "add" || op == "replace" || op == "test") {
+			operation["value"] = map[string]interface{}{}
+		}
+	}
+	return items
+}
+
+// binaryPartNames lists the binary parts of a multipart/related body
+func binaryPartNames(parts []types.BodyPart) []string {
+	var names []string
+	for _, part := range parts {
+		if part.Binary {
+			names = append(names, part.Name)
+		}
+	}
+	return names
+}
+
+// assignContentIDs points the RefToBinaryData contentIds of a generated multipart body at the
+// declared binary parts: the part named after the field or one of its parents
+// (binaryDataForwardRelocationRequest for forwardRelocationRequest), else the first unused part.
+// The content_id of a configured part defaults to its name, so the generated body matches it.
+func assignContentIDs(example interface{}, partNames []string) {
+	if len(partNames) == 0 {
+		return
+	}
+	used := make(map[string]bool)
+	var walk func(value interface{}, fields []string)
+	walk = func(value interface{}, fields []string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if _, isRef := v["contentId"].(string); isRef {
+				name := matchPartName(fields, partNames, used)
+				used[name] = true
+				v["contentId"] = name
+				return
+			}
+			for _, key := range sortedKeys(v) {
+				walk(v[key], append(fields, key))
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item, fields)
+			}
+		}
+	}
+	walk(example, nil)
+}
+
+// matchPartName returns the binary part named after the innermost field of fields,
+// else the first part not used yet
+func matchPartName(fields []string, partNames []string, used map[string]bool) string {
+	for i := len(fields) - 1; i >= 0; i-- {
+		field := strings.ToLower(fields[i])
+		for _, name := range partNames {
+			suffix := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(name, "binary"), "Data"))
+			if suffix != "" && (strings.Contains(suffix, field) || strings.Contains(field, suffix)) {
+				return name
+			}
+		}
+	}
+	for _, name := range partNames {
+		if !used[name] {
+			return name
+		}
+	}
+	return partNames[0]
+}
+
+// jsonSchemaPath returns the path of the JSON schema in a content map: the schema of the
+// JSON media type, or of the JSON part of a multipart/related body. Nil if there is none.
+func jsonSchemaPath(content map[string]interface{}) []string {
+	if contentType := selectJSONContentType(content); contentType != "" {
+		return []string{contentType, "schema"}
+	}
+
+	properties, _ := lookupPath(content, "multipart/related", "schema", "properties")
+	parts, _ := properties.(map[string]interface{})
+	for _, part := range sortedKeys(parts) {
+		if encoding, _ := lookupPath(content, "multipart/related", "encoding", part, "contentType"); encoding == "application/json" {
+			return []string{"multipart/related", "schema", "properties", part}
+		}
+	}
+	for _, part := range sortedKeys(parts) {
+		if _, isRef := lookupPath(parts, part, "$ref"); isRef {
+			return []string{"multipart/related", "schema", "properties", part}
+		}
+	}
+	return nil
+}
+
+// selectJSONContentType prefers application/json over other JSON media types
+func selectJSONContentType(content map[string]interface{}) string {
+	if _, exists := content["application/json"]; exists {
+		return "application/json"
+	}
+	for _, contentType := range sortedKeys(content) {
+		if strings.Contains(contentType, "json") {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// generate returns an example value for schema, false if none can be built
+// (unresolvable or recursive references)
+func (g *bodyGenerator) generate(schema interface{}, file string, depth int) (interface{}, bool) {
+	m, ok := schema.(map[string]interface{})
+	if !ok || depth > maxExampleDepth {
+		return nil, false
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		return g.generateRef(ref, file, depth)
+	}
+
+	if value, exists := m["const"]; exists {
+		return value, true
+	}
+	if example, exists := m["example"]; exists && example != nil {
+		return example, true
+	}
+	if examples, ok := m["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0], true
+	}
+	if enum, ok := m["enum"].([]interface{}); ok {
+		for _, value := range enum {
+			if value != nil {
+				return value, true
+			}
+		}
+	}
+
+	m = withFirstAlternative(m)
+	if _, ok := m["allOf"].([]interface{}); ok {
+		return g.generateAllOf(m, file, depth)
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := m[keyword].([]interface{}); ok {
+			for _, alternative := range alternatives {
+				if value, ok := g.generate(alternative, file, depth+1); ok && value != nil {
+					return value, true
+				}
+			}
+			return nil, false
+		}
+	}
+
+	switch schemaType(m) {
+	case "object":
+		return g.generateObject(m, objectProperties(m, file), requiredFields(m), file, depth)
+	case "array":
+		return g.generateArray(m, file, depth)
+	case "string":
+		return exampleString(m), true
+	case "integer":
+		return int64(exampleNumber(m, true)), true
+	case "number":
+		return exampleNumber(m, false), true
+	case "boolean":
+		return false, true
+	case "null":
+		return nil, true
+	}
+
+	// Schemas without any constraint accept any value
+	return map[string]interface{}{}, true
+}
+
+// withFirstAlternative turns an object schema constrained by oneOf/anyOf (typically
+// alternative lists of required fields) into one whose first alternative is an allOf member
+func withFirstAlternative(m map[string]interface{}) map[string]interface{} {
+	if m["properties"] == nil && m["required"] == nil && schemaType(m) != "object" {
+		return m
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		alternatives, ok := m[keyword].([]interface{})
+		if !ok || len(alternatives) == 0 {
+			continue
+		}
+
+		merged := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			if key != "oneOf" && key != "anyOf" {
+				merged[key] = value
+			}
+		}
+		allOf, _ := m["allOf"].([]interface{})
+		merged["allOf"] = append(append([]interface{}{}, allOf...), alternatives[0])
+		return merged
+	}
+	return m
+}
+
+// generateRef generates the target of a $ref, stopping at recursion
+func (g *bodyGenerator) generateRef(ref, file string, depth int) (interface{}, bool) {
+	refFile, pointer, _ := strings.Cut(ref, "#")
+	key := file + "#" + pointer
+	if refFile != "" {
+		key = path.Join(path.Dir(file), refFile) + "#" + pointer
+	}
+	if g.visiting[key] {
+		return nil, false
+	}
+
+	target, targetFile, err := g.resolver.resolve(file, ref)
+	if err != nil {
+		return nil, false
+	}
+
+	g.visiting[key] = true
+	defer delete(g.visiting, key)
+	return g.generate(target, targetFile, depth+1)
+}
+
+// propertySchema is a property schema with the file its $refs are relative to
+type propertySchema struct {
+	schema interface{}
+	file   string
+}
+
+// objectProperties returns the properties of an object schema
+func objectProperties(m map[string]interface{}, file string) map[string]propertySchema {
+	properties := make(map[string]propertySchema)
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		for name, schema := range props {
+			properties[name] = propertySchema{schema: schema, file: file}
+		}
+	}
+	return properties
+}
+
+// requiredFields returns the required property names of a schema
+func requiredFields(m map[string]interface{}) []string {
+	var required []string
+	if list, ok := m["required"].([]interface{}); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				required = append(required, name)
+			}
+		}
+	}
+	return required
+}
+
+// generateAllOf merges the properties and required fields of all members into one object,
+// so a field required by one member and defined by another is still generated
+func (g *bodyGenerator) generateAllOf(m map[string]interface{}, file string, depth int) (interface{}, bool) {
+	properties := objectProperties(m, file)
+	required := requiredFields(m)
+	var first interface{}
+	firstFile := file
+	isObject := schemaType(m) == "object"
+
+	members, _ := m["allOf"].([]interface{})
+	g.walkAllOf(members, file, depth+1, func(member map[string]interface{}, memberFile string) {
+		if first == nil {
+			first, firstFile = member, memberFile
+		}
+		if schemaType(member) == "object" {
+			isObject = true
+		}
+		for name, prop := range objectProperties(member, memberFile) {
+			if _, exists := properties[name]; !exists {
+				properties[name] = prop
+			}
+		}
+		required = append(required, requiredFields(member)...)
+	})
+
+	if isObject || len(properties) > 0 || len(required) > 0 {
+		return g.generateObject(m, properties, required, file, depth)
+	}
+	if first != nil {
+		return g.generate(first, firstFile, depth+1)
+	}
+	return map[string]interface{}{}, true
+}
+
+// walkAllOf calls visit for every schema merged by allOf members, following $refs and nested allOf
+func (g *bodyGenerator) walkAllOf(members []interface{}, file string, depth int, visit func(member map[string]interface{}, memberFile string)) {
+	for _, member := range members {
+		memberMap, ok := member.(map[string]interface{})
+		if !ok || depth > maxExampleDepth {
+			continue
+		}
+
+		memberFile := file
+		if ref, ok := memberMap["$ref"].(string); ok {
+			target, targetFile, err := g.resolver.resolve(file, ref)
+			if err != nil {
+				continue
+			}
+			g.walkAllOf([]interface{}{target}, targetFile, depth+1, visit)
+			continue
+		}
+
+		visit(memberMap, memberFile)
+		if nested, ok := memberMap["allOf"].([]interface{}); ok {
+			g.walkAllOf(nested, memberFile, depth+1, visit)
+		}
+	}
+}
+
+// generateObject generates the required properties of an object, and one
+// entry of a map type (additionalProperties) when it must not be empty
+func (g *bodyGenerator) generateObject(m map[string]interface{}, properties map[string]propertySchema, required []string, file string, depth int) (interface{}, bool) {
+	result := make(map[string]interface{})
+
+	for _, name := range required {
+		if _, done := result[name]; done {
+			continue
+		}
+		prop, exists := properties[name]
+		if !exists {
+			result[name] = "string" // Required without a schema: any value is valid
+			continue
+		}
+		if value, ok := g.generate(prop.schema, prop.file, depth+1); ok {
+			result[name] = value
+		}
+	}
+
+	if minProperties, ok := numberKeyword(m, "minProperties"); ok && len(result) < int(minProperties) {
+		if additional, ok := m["additionalProperties"].(map[string]interface{}); ok {
+			for i := len(result) + 1; len(result) < int(minProperties); i++ {
+				value, ok := g.generate(additional, file, depth+1)
+				if !ok {
+					break
+				}
+				result[fmt.Sprintf("key%d", i)] = value
+			}
+		}
+	}
+
+	return result, true
+}
+
+// generateArray generates minItems items (at least one unless maxItems is 0)
+func (g *bodyGenerator) generateArray(m map[string]interface{}, file string, depth int) (interface{}, bool) {
+	count := 1
+	if minItems, ok := numberKeyword(m, "minItems"); ok && int(minItems) > count {
+		count = int(minItems)
+	}
+	if maxItems, ok := numberKeyword(m, "maxItems"); ok && int(maxItems) < count {
+		count = int(maxItems)
+	}
+
+	result := make([]interface{}, 0, count)
+	items, hasItems := m["items"]
+	if !hasItems {
+		for len(result) < count {
+			result = append(result, "string")
+		}
+		return result, true
+	}
+
+	for len(result) < count {
+		value, ok := g.generate(items, file, depth+1)
+		if !ok {
+			break
+		}
+		result = append(result, value)
+	}
+	return result, true
+}
+
+// schemaType returns the declared type, the first non-null one of a 3.1 type array,
+// or the type implied by object/array keywords
+func schemaType(m map[string]interface{}) string {
+	switch t := m["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+		return "null"
+	}
+
+	switch {
+	case m["properties"] != nil || m["additionalProperties"] != nil || m["required"] != nil:
+		return "object"
+	case m["items"] != nil:
+		return "array"
+	case m["pattern"] != nil || m["format"] != nil || m["minLength"] != nil || m["maxLength"] != nil:
+		return "string"
+	}
+	return ""
+}
+
+// numberKeyword reads a numeric schema keyword
+func numberKeyword(m map[string]interface{}, key string) (float64, bool) {
+	switch v := m[key].(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// exampleNumber picks a value within minimum/maximum (3.0 boolean and 3.1 numeric exclusive bounds)
+func exampleNumber(m map[string]interface{}, integer bool) float64 {
+	step := 1.0
+	if !integer {
+		step = 0.5
+	}
+
+	value := 0.0
+	if minimum, ok := numberKeyword(m, "minimum"); ok {
+		value = minimum
+		if exclusive, _ := m["exclusiveMinimum"].(bool); exclusive {
+			value += step
+		}
+	} else if exclusiveMinimum, ok := numberKeyword(m, "exclusiveMinimum"); ok {
+		value = exclusiveMinimum + step
+	} else if maximum, ok := numberKeyword(m, "maximum"); ok && maximum < 0 {
+		value = maximum
+		if exclusive, _ := m["exclusiveMaximum"].(bool); exclusive {
+			value -= step
+		}
+	} else if exclusiveMaximum, ok := numberKeyword(m, "exclusiveMaximum"); ok && exclusiveMaximum <= 0 {
+		value = exclusiveMaximum - step
+	}
+
+	if multipleOf, ok := numberKeyword(m, "multipleOf"); ok && multipleOf > 0 {
+		value = math.Ceil(value/multipleOf) * multipleOf
+	}
+	if integer {
+		value = math.Ceil(value)
+	}
+	return value
+}
+
+// exampleString builds a string matching pattern, format and length constraints
+func exampleString(m map[string]interface{}) string {
+	value := ""
+	pattern, _ := m["pattern"].(string)
+
+	if pattern != "" {
+		if generated, ok := stringFromPattern(pattern); ok {
+			value = generated
+		}
+	}
+
+	if value == "" {
+		format, _ := m["format"].(string)
+		value = exampleStringByFormat(format)
+	}
+
+	if minLength, ok := numberKeyword(m, "minLength"); ok && len(value) < int(minLength) {
+		value += strings.Repeat("a", int(minLength)-len(value))
+	}
+	if maxLength, ok := numberKeyword(m, "maxLength"); ok && len(value) > int(maxLength) {
+		value = value[:int(maxLength)]
+	}
+
+	return value
+}
+
+// exampleStringByFormat returns a valid value for common string formats
+func exampleStringByFormat(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00Z"
+	case "uuid":
+		return "550e8400-e29b-41d4-a716-446655440000"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	case "uri", "uri-reference", "url":
+		return "http://example.com"
+	case "email":
+		return "user@example.com"
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	case "hostname":
+		return "example.com"
+	default:
+		return "string"
+	}
+}
+
+// stringFromPattern builds the shortest string matching a regular expression,
+// taking the first alternative and the minimum repetition everywhere
+func stringFromPattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	writePatternExample(&b, re)
+	value := b.String()
+
+	if matched, err := regexp.MatchString(pattern, value); err != nil || !matched {
+		return "", false
+	}
+	return value, true
+}
+
+// writePatternExample writes a minimal match of re
+func writePatternExample(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classExampleRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture:
+		writePatternExample(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePatternExample(b, sub)
+		}
+	case syntax.OpAlternate:
+		writePatternExample(b, re.Sub[0])
+	case syntax.OpPlus:
+		writePatternExample(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			writePatternExample(b, re.Sub[0])
+		}
+	}
+	// OpStar, OpQuest, anchors and empty matches contribute nothing
+}
+
+// classExampleRune picks a readable rune of a character class given as ranges
+func classExampleRune(ranges []rune) rune {
+	inClass := func(r rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if r >= ranges[i] && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, candidate := range "a0A1-_." {
+		if inClass(candidate) {
+			return candidate
+		}
+	}
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r-ranges[i] < 256; r++ {
+			if unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				return r
+			}
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'a'
+}
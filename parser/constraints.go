@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// resolveConstraints records the resolved type and enumeration of the parameters and
+// top-level request body fields of every API of service, so configuration values can
+// be checked without the specifications
+func (g *bodyGenerator) resolveConstraints(specPath string, service types.ServiceMetadata) {
+	doc, err := g.resolver.document(specPath)
+	if err != nil {
+		return
+	}
+
+	for name, api := range service.APIs {
+		if len(api.Methods) == 0 {
+			continue
+		}
+		pathItem, _ := lookupPath(doc, "paths", api.Path)
+		operation, _ := lookupPath(pathItem, strings.ToLower(api.Methods[0]))
+
+		// Path-level parameters first, operation parameters override them by name
+		parameters := make(map[string]types.ValueConstraint)
+		for _, node := range []interface{}{pathItem, operation} {
+			list, _ := lookupPath(node, "parameters")
+			items, _ := list.([]interface{})
+			for _, item := range items {
+				param, paramFile := g.deref(item, specPath, 0)
+				paramName, _ := param["name"].(string)
+				schema, hasSchema := param["schema"]
+				if paramName == "" || !hasSchema {
+					continue
+				}
+				if constraint := g.valueConstraint(schema, paramFile, 0); constraint.Type != "" || len(constraint.Enum) > 0 {
+					parameters[paramName] = constraint
+				}
+			}
+		}
+		if len(parameters) > 0 {
+			api.ParameterConstraints = parameters
+		}
+
+		if fields := g.requestBodyFields(operation, specPath); len(fields) > 0 {
+			api.RequestBodyFields = fields
+		}
+
+		service.APIs[name] = api
+	}
+}
+
+// requestBodyFields resolves the top-level properties of the JSON request body of operation
+func (g *bodyGenerator) requestBodyFields(operation interface{}, file string) map[string]types.ValueConstraint {
+	requestBodyNode, ok := lookupPath(operation, "requestBody")
+	if !ok {
+		return nil
+	}
+	requestBody, file := g.deref(requestBodyNode, file, 0)
+
+	content, _ := requestBody["content"].(map[string]interface{})
+	schemaPath := jsonSchemaPath(content)
+	if schemaPath == nil {
+		return nil
+	}
+	schemaNode, _ := lookupPath(content, schemaPath...)
+	schema, file := g.deref(schemaNode, file, 0)
+	if schema == nil {
+		return nil
+	}
+
+	properties := objectProperties(schema, file)
+	members, _ := schema["allOf"].([]interface{})
+	g.walkAllOf(members, file, 1, func(member map[string]interface{}, memberFile string) {
+		for name, prop := range objectProperties(member, memberFile) {
+			if _, exists := properties[name]; !exists {
+				properties[name] = prop
+			}
+		}
+	})
+
+	fields := make(map[string]types.ValueConstraint)
+	for name, prop := range properties {
+		if constraint := g.valueConstraint(prop.schema, prop.file, 0); constraint.Type != "" || len(constraint.Enum) > 0 {
+			fields[name] = constraint
+		}
+	}
+	return fields
+}
+
+// deref follows $refs until a schema (or parameter, request body) object is reached
+func (g *bodyGenerator) deref(node interface{}, file string, depth int) (map[string]interface{}, string) {
+	m, ok := node.(map[string]interface{})
+	if !ok || depth > maxExampleDepth {
+		return nil, file
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		target, targetFile, err := g.resolver.resolve(file, ref)
+		if err != nil {
+			return nil, file
+		}
+		return g.deref(target, targetFile, depth+1)
+	}
+	return m, file
+}
+
+// valueConstraint resolves the type and enumeration of a schema, following $refs
+// and the anyOf [enum, string] pattern 3GPP uses for extensible enumerations
+func (g *bodyGenerator) valueConstraint(schema interface{}, file string, depth int) types.ValueConstraint {
+	m, file := g.deref(schema, file, depth)
+	if m == nil {
+		return types.ValueConstraint{}
+	}
+
+	if members, ok := m["allOf"].([]interface{}); ok && len(members) == 1 && m["properties"] == nil {
+		return g.valueConstraint(members[0], file, depth+1)
+	}
+
+	constraint := types.ValueConstraint{Type: schemaType(m)}
+	if enum, ok := m["enum"].([]interface{}); ok {
+		for _, value := range enum {
+			if value != nil {
+				constraint.Enum = append(constraint.Enum, value)
+			}
+		}
+		if len(constraint.Enum) == 0 {
+			constraint.Type = "null" // NullValue
+		} else if _, isString := constraint.Enum[0].(string); isString && constraint.Type == "" {
+			constraint.Type = "string"
+		}
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		alternatives, ok := m[keyword].([]interface{})
+		if !ok || constraint.Type != "" {
+			continue
+		}
+
+		altTypes := make(map[string]bool)
+		var enum []interface{}
+		extensible := false
+		for _, alternative := range alternatives {
+			alt := g.valueConstraint(alternative, file, depth+1)
+			switch {
+			case alt.Type == "null":
+				continue
+			case alt.Type == "":
+				return types.ValueConstraint{} // Unconstrained alternative
+			}
+			altTypes[alt.Type] = true
+			if len(alt.Enum) == 0 || alt.Extensible {
+				extensible = true
+			}
+			enum = append(enum, alt.Enum...)
+		}
+
+		if len(altTypes) == 1 {
+			for t := range altTypes {
+				constraint.Type = t
+			}
+			if len(enum) > 0 {
+				constraint.Enum, constraint.Extensible = enum, extensible
+			}
+		}
+	}
+
+	return constraint
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isSpecFile checks if file has a YAML or JSON extension
+func isSpecFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// decodeDocument parses a YAML or JSON specification into a node tree.
+// JSON is not read with the YAML parser because valid JSON such as "\/" escapes
+// or tab indentation is rejected by it.
+func decodeDocument(fileName string, data []byte) (*yaml.Node, error) {
+	if strings.ToLower(filepath.Ext(fileName)) != ".json" {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return &doc, nil
+	}
+
+	decoder := &jsonNodeDecoder{dec: json.NewDecoder(bytes.NewReader(data))}
+	decoder.dec.UseNumber()
+	for i, b := range data {
+		if b == '\n' {
+			decoder.lineStarts = append(decoder.lineStarts, i+1)
+		}
+	}
+
+	root, err := decoder.value()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document (line %d)", decoder.line())
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}, Line: 1, Column: 1}, nil
+}
+
+// jsonNodeDecoder converts a JSON token stream into yaml.Node values with line numbers
+type jsonNodeDecoder struct {
+	dec        *json.Decoder
+	lineStarts []int
+}
+
+// line returns the 1-based line of the last token read
+func (d *jsonNodeDecoder) line() int {
+	offset := int(d.dec.InputOffset()) - 1
+	return sort.SearchInts(d.lineStarts, offset+1) + 1
+}
+
+// value reads one JSON value
+func (d *jsonNodeDecoder) value() (*yaml.Node, error) {
+	token, err := d.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("unexpected end of JSON document")
+		}
+		return nil, err
+	}
+	line := d.line()
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+			for d.dec.More() {
+				keyToken, err := d.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprintf("%v", keyToken), Line: d.line()}
+				val, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, key, val)
+			}
+			_, err := d.dec.Token() // closing '}'
+			return node, err
+		case '[':
+			node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+			for d.dec.More() {
+				item, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+			_, err := d.dec.Token() // closing ']'
+			return node, err
+		}
+		return nil, fmt.Errorf("unexpected '%v' at line %d", t, line)
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t, Style: yaml.DoubleQuotedStyle, Line: line}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String(), Line: line}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", t), Line: line}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null", Line: line}, nil
+	}
+}
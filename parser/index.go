@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// DefaultIndexPath is where the compiled spec index is stored
+const DefaultIndexPath = ".ctrlbench/spec_index.gob"
+
+// specIndexVersion must be bumped whenever the metadata model changes,
+// so indexes written by older binaries are rebuilt instead of misread
+const specIndexVersion = 5
+
+// generatedFiles are written into the openapi directory by the tool itself
+// and never affect the parsed specifications
+var generatedFiles = map[string]bool{"api_list.yaml": true}
+
+// nfMappingKey stores the fingerprint of the nf_mapping.yaml overrides next to the file hashes
+const nfMappingKey = "#nf_mapping"
+
+// specIndex is the on-disk form of the parsed release bundles
+type specIndex struct {
+	Version int
+	Hashes  map[string]string // spec file path → sha256, plus the NF mapping fingerprint
+	Bundles map[string]map[string]types.ServiceMetadata
+}
+
+func init() {
+	// Concrete types found in interface{} fields of the decoded specifications
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// ParseOpenAPIBundlesCached returns the release bundles of dir, reusing the index
+// at indexPath while the hashes of the spec files match and rebuilding it otherwise
+func ParseOpenAPIBundlesCached(fsys fs.FS, dir, indexPath string) (map[string]map[string]types.ServiceMetadata, error) {
+	hashes, err := hashSpecFiles(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	hashes[nfMappingKey] = nfMappingFingerprint()
+
+	if index, err := readSpecIndex(indexPath); err == nil && sameHashes(index.Hashes, hashes) {
+		return index.Bundles, nil
+	}
+
+	bundles, err := ParseOpenAPIBundles(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bundles) > 0 {
+		if err := writeSpecIndex(indexPath, &specIndex{Version: specIndexVersion, Hashes: hashes, Bundles: bundles}); err != nil {
+			fmt.Printf("⚠️  Failed to write spec index %s: %v\n", indexPath, err)
+		}
+	}
+
+	return bundles, nil
+}
+
+// hashSpecFiles hashes the spec files of dir and of its rel-* subdirectories
+func hashSpecFiles(fsys fs.FS, dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+
+	dirs := []string{dir}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), releaseDirPrefix) {
+			dirs = append(dirs, path.Join(dir, entry.Name()))
+		}
+	}
+
+	for _, specDir := range dirs {
+		files, err := fs.ReadDir(fsys, specDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read openapi dir: %w", err)
+		}
+		for _, fi := range files {
+			if fi.IsDir() || !isSpecFile(fi.Name()) || generatedFiles[fi.Name()] {
+				continue
+			}
+
+			filePath := path.Join(specDir, fi.Name())
+			data, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return nil, err
+			}
+			sum := sha256.Sum256(data)
+			hashes[filePath] = hex.EncodeToString(sum[:])
+		}
+	}
+
+	return hashes, nil
+}
+
+// sameHashes checks if two sets of spec file hashes are identical
+func sameHashes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, hash := range a {
+		if b[file] != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// readSpecIndex loads the index, rejecting indexes of another format version
+func readSpecIndex(indexPath string) (*specIndex, error) {
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var index specIndex
+	if err := gob.NewDecoder(file).Decode(&index); err != nil {
+		return nil, err
+	}
+	if index.Version != specIndexVersion {
+		return nil, fmt.Errorf("spec index version %d, expected %d", index.Version, specIndexVersion)
+	}
+
+	return &index, nil
+}
+
+// writeSpecIndex stores the index atomically so a concurrent run never reads a partial file
+func writeSpecIndex(indexPath string, index *specIndex) error {
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(indexPath), filepath.Base(indexPath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(index); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), indexPath)
+}
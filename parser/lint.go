@@ -0,0 +1,595 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint rules
+const (
+	lintParseError             = "parse-error"
+	lintUnresolvedRef          = "unresolved-ref"
+	lintMissingOperationID     = "missing-operation-id"
+	lintDuplicateName          = "duplicate-name"
+	lintUnknownNF              = "unknown-nf"
+	lintUnsupportedSchema      = "unsupported-schema"
+	lintUnsupportedContentType = "unsupported-content-type"
+)
+
+// LintOpenAPIDir reports what the parser will reject or silently mis-handle in an openapi directory
+func LintOpenAPIDir(dirPath string) (*types.LintReport, error) {
+	return LintOpenAPIFS(os.DirFS(dirPath), ".")
+}
+
+// LintOpenAPIFS lints the unversioned bundle in dir and every rel-* release bundle below it
+func LintOpenAPIFS(fsys fs.FS, dir string) (*types.LintReport, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi dir: %w", err)
+	}
+
+	report := &types.LintReport{}
+	lintBundle(fsys, dir, "", report)
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), releaseDirPrefix) {
+			lintBundle(fsys, path.Join(dir, entry.Name()), entry.Name(), report)
+		}
+	}
+
+	for _, file := range report.Files {
+		for _, issue := range file.Issues {
+			if issue.Severity == types.LintError {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// bundleLinter holds state shared by the files of one bundle
+type bundleLinter struct {
+	fsys      fs.FS
+	dir       string
+	documents map[string]*yaml.Node
+	loadErrs  map[string]error
+}
+
+// lintedService records the service a file maps to, for cross-file checks
+type lintedService struct {
+	index    int // Index of the file in the report
+	apiNames []string
+}
+
+// lintBundle lints all spec files directly in dir
+func lintBundle(fsys fs.FS, dir, release string, report *types.LintReport) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return
+	}
+
+	linter := &bundleLinter{
+		fsys:      fsys,
+		dir:       dir,
+		documents: make(map[string]*yaml.Node),
+		loadErrs:  make(map[string]error),
+	}
+
+	first := len(report.Files)
+	services := make(map[string]*lintedService)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isSpecFile(entry.Name()) {
+			continue
+		}
+
+		fileReport, service, apiNames := linter.lintFile(entry.Name(), release)
+		if fileReport == nil {
+			continue
+		}
+		if service != "" {
+			if existing, exists := services[service]; exists {
+				fileReport.Issues = append(fileReport.Issues, types.LintIssue{
+					Severity: types.LintWarning,
+					Rule:     lintDuplicateName,
+					Message: fmt.Sprintf("service name '%s' is also derived from %s, this file is registered as '%s@%s'",
+						service, report.Files[existing.index].File, service, specFileStem(entry.Name())),
+				})
+			} else {
+				services[service] = &lintedService{index: len(report.Files), apiNames: apiNames}
+			}
+		}
+		report.Files = append(report.Files, *fileReport)
+	}
+
+	lintAmbiguousAPIs(report.Files, services)
+
+	for i := first; i < len(report.Files); i++ {
+		sortLintIssues(report.Files[i].Issues)
+	}
+}
+
+// lintAmbiguousAPIs reports API names shared by several services of the same NF
+func lintAmbiguousAPIs(files []types.LintFileReport, services map[string]*lintedService) {
+	owners := make(map[string][]string)
+	for _, service := range sortedKeys(services) {
+		seen := make(map[string]bool)
+		for _, apiName := range services[service].apiNames {
+			key := files[services[service].index].NF + "/" + apiName
+			if !seen[key] {
+				owners[key] = append(owners[key], service)
+				seen[key] = true
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(owners) {
+		serviceNames := owners[key]
+		if len(serviceNames) < 2 {
+			continue
+		}
+		nf, apiName, _ := strings.Cut(key, "/")
+		for _, service := range serviceNames[1:] {
+			fileReport := &files[services[service].index]
+			fileReport.Issues = append(fileReport.Issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintDuplicateName,
+				Message: fmt.Sprintf("API name '%s' is also defined by service '%s' of NF %s and must be addressed with -s or its key",
+					apiName, serviceNames[0], nf),
+			})
+		}
+	}
+}
+
+// lintFile lints one file and returns its report, the service name it maps to and its API names
+func (l *bundleLinter) lintFile(fileName, release string) (*types.LintFileReport, string, []string) {
+	filePath := path.Join(l.dir, fileName)
+	fileReport := &types.LintFileReport{File: filePath, Release: release, Issues: []types.LintIssue{}}
+
+	root, err := l.document(filePath)
+	if err != nil {
+		fileReport.Issues = append(fileReport.Issues, types.LintIssue{
+			Severity: types.LintError,
+			Rule:     lintParseError,
+			Message:  err.Error(),
+		})
+		return fileReport, "", nil
+	}
+
+	var spec types.OpenAPISpec
+	if err := root.Decode(&spec); err != nil {
+		fileReport.Issues = append(fileReport.Issues, types.LintIssue{
+			Severity: types.LintError,
+			Rule:     lintParseError,
+			Message:  fmt.Sprintf("document does not match the OpenAPI model: %v", err),
+		})
+		return fileReport, "", nil
+	}
+
+	// Generated files such as api_list.yaml are not specifications
+	if spec.OpenAPI == "" {
+		return nil, "", nil
+	}
+
+	fileReport.NF = extractNFName(&spec, fileName)
+	fileReport.Service = cleanServiceName(extractServiceName(&spec, fileName))
+
+	if fileReport.NF == "UNKNOWN" && len(spec.Paths) > 0 {
+		fileReport.Issues = append(fileReport.Issues, types.LintIssue{
+			Severity: types.LintError,
+			Rule:     lintUnknownNF,
+			Line:     nodeLine(mappingValue(root, "servers")),
+			Location: "/servers",
+			Message: fmt.Sprintf("NF could not be derived from the file name (TS29xxx_N<nf>_<Service>), the server URL or the OAuth2 scopes, "+
+				"its APIs are listed under UNKNOWN (map '%s' under files: in nf_mapping.yaml)", specFileStem(fileName)),
+		})
+	}
+
+	fileReport.Issues = append(fileReport.Issues, l.lintRefs(filePath, root)...)
+	issues, apiNames := l.lintOperations(filePath, root, &spec)
+	fileReport.Issues = append(fileReport.Issues, issues...)
+
+	return fileReport, fileReport.Service, apiNames
+}
+
+// unresolvedRef aggregates occurrences of one unresolved $ref in a file
+type unresolvedRef struct {
+	line     int
+	location string
+	count    int
+	reason   string
+}
+
+// lintRefs reports every distinct $ref of the file that does not resolve
+func (l *bundleLinter) lintRefs(filePath string, root *yaml.Node) []types.LintIssue {
+	unresolved := make(map[string]*unresolvedRef)
+
+	walkRefs(root, "", func(ref string, node *yaml.Node, pointer string) {
+		if existing, exists := unresolved[ref]; exists {
+			existing.count++
+			return
+		}
+		if _, err := l.resolveRef(filePath, ref); err != nil {
+			unresolved[ref] = &unresolvedRef{line: node.Line, location: pointer, count: 1, reason: err.Error()}
+		}
+	})
+
+	var issues []types.LintIssue
+	for _, ref := range sortedKeys(unresolved) {
+		u := unresolved[ref]
+		message := fmt.Sprintf("unresolved $ref '%s': %s", ref, u.reason)
+		if u.count > 1 {
+			message += fmt.Sprintf(" (%d occurrences)", u.count)
+		}
+		issues = append(issues, types.LintIssue{
+			Severity: types.LintError,
+			Rule:     lintUnresolvedRef,
+			Line:     u.line,
+			Location: u.location,
+			Message:  message,
+		})
+	}
+	return issues
+}
+
+// lintOperations checks operation naming, parameters and request bodies
+func (l *bundleLinter) lintOperations(filePath string, root *yaml.Node, spec *types.OpenAPISpec) ([]types.LintIssue, []string) {
+	var issues []types.LintIssue
+	var apiNames []string
+
+	operationIDs := make(map[string]string)
+	generatedNames := make(map[string]string)
+	pathsNode := mappingValue(root, "paths")
+
+	for _, apiPath := range sortedKeys(spec.Paths) {
+		pathNode := mappingValue(pathsNode, apiPath)
+
+		if params := mappingValue(pathNode, "parameters"); params != nil {
+			issues = append(issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintUnsupportedSchema,
+				Line:     params.Line,
+				Location: jsonPointer("", "paths", apiPath, "parameters"),
+				Message:  "path-level parameters are not modeled by the parser and are ignored for every operation of this path",
+			})
+		}
+
+		for _, method := range httpMethods {
+			opNode := mappingValue(pathNode, strings.ToLower(method))
+			if opNode == nil {
+				continue
+			}
+			location := jsonPointer("", "paths", apiPath, strings.ToLower(method))
+
+			var operation types.Operation
+			if err := opNode.Decode(&operation); err != nil {
+				continue
+			}
+
+			name := getAPIName(&operation, method, apiPath)
+			apiNames = append(apiNames, name)
+
+			if operation.OperationID == "" {
+				issues = append(issues, types.LintIssue{
+					Severity: types.LintWarning,
+					Rule:     lintMissingOperationID,
+					Line:     opNode.Line,
+					Location: location,
+					Message:  fmt.Sprintf("%s %s has no operationId, the generated name '%s' is used", method, apiPath, name),
+				})
+				if other, exists := generatedNames[name+" "+method]; exists {
+					issues = append(issues, types.LintIssue{
+						Severity: types.LintWarning,
+						Rule:     lintDuplicateName,
+						Line:     opNode.Line,
+						Location: location,
+						Message:  fmt.Sprintf("generated name '%s [%s]' is also generated for %s, the API is qualified with its path", name, method, other),
+					})
+				}
+				generatedNames[name+" "+method] = apiPath
+			} else {
+				if other, exists := operationIDs[operation.OperationID]; exists {
+					issues = append(issues, types.LintIssue{
+						Severity: types.LintError,
+						Rule:     lintDuplicateName,
+						Line:     opNode.Line,
+						Location: location,
+						Message:  fmt.Sprintf("operationId '%s' is also used by %s", operation.OperationID, other),
+					})
+				}
+				operationIDs[operation.OperationID] = method + " " + apiPath
+			}
+
+			issues = append(issues, l.lintParameters(opNode, location)...)
+			issues = append(issues, l.lintRequestBody(filePath, opNode, location)...)
+		}
+	}
+
+	return issues, apiNames
+}
+
+// lintParameters reports parameters the parser cannot represent
+func (l *bundleLinter) lintParameters(opNode *yaml.Node, location string) []types.LintIssue {
+	var issues []types.LintIssue
+
+	params := mappingValue(opNode, "parameters")
+	if params == nil || params.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for i, param := range params.Content {
+		paramLocation := jsonPointer(location, "parameters", strconv.Itoa(i))
+
+		if ref := mappingValue(param, "$ref"); ref != nil {
+			issues = append(issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintUnsupportedSchema,
+				Line:     ref.Line,
+				Location: paramLocation,
+				Message:  fmt.Sprintf("parameter $ref '%s' is not resolved by the parser, the parameter has no name", ref.Value),
+			})
+		}
+	}
+
+	return issues
+}
+
+// lintRequestBody reports request bodies the parser drops or approximates
+func (l *bundleLinter) lintRequestBody(filePath string, opNode *yaml.Node, location string) []types.LintIssue {
+	body := mappingValue(opNode, "requestBody")
+	if body == nil {
+		return nil
+	}
+
+	bodyLocation := jsonPointer(location, "requestBody")
+	if ref := mappingValue(body, "$ref"); ref != nil {
+		return []types.LintIssue{{
+			Severity: types.LintWarning,
+			Rule:     lintUnsupportedSchema,
+			Line:     ref.Line,
+			Location: bodyLocation,
+			Message:  fmt.Sprintf("request body $ref '%s' is not resolved by the parser, the body is dropped", ref.Value),
+		}}
+	}
+
+	content := mappingValue(body, "content")
+	if content == nil {
+		return nil
+	}
+
+	var issues []types.LintIssue
+	hasJSON := false
+
+	for _, contentType := range mappingKeys(content) {
+		mediaNode := mappingValue(content, contentType)
+		mediaLocation := jsonPointer(bodyLocation, "content", contentType)
+
+		if contentType == "multipart/related" {
+			hasJSON = true // Sent with its JSON part and configured binary parts
+			continue
+		}
+		if !strings.Contains(contentType, "json") {
+			issues = append(issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintUnsupportedContentType,
+				Line:     mediaNode.Line,
+				Location: mediaLocation,
+				Message:  fmt.Sprintf("content type '%s' is not supported, only JSON and multipart/related request bodies are sent", contentType),
+			})
+			continue
+		}
+		hasJSON = true
+
+		schema := mappingValue(mediaNode, "schema")
+		if schema == nil {
+			continue
+		}
+
+		if ref := mappingValue(schema, "$ref"); ref != nil {
+			target, err := l.resolveRef(filePath, ref.Value)
+			if err != nil {
+				continue // already reported as unresolved-ref
+			}
+			// allOf members are merged, only the first oneOf/anyOf alternative is generated
+			for _, keyword := range []string{"oneOf", "anyOf"} {
+				if mappingValue(target, keyword) != nil {
+					issues = append(issues, types.LintIssue{
+						Severity: types.LintWarning,
+						Rule:     lintUnsupportedSchema,
+						Line:     ref.Line,
+						Location: mediaLocation,
+						Message:  fmt.Sprintf("schema '%s' uses %s, the generated body follows its first alternative, the others need overrides", extractSchemaNameFromRef(ref.Value), keyword),
+					})
+				}
+			}
+			continue
+		}
+
+		schemaType := scalarValue(mappingValue(schema, "type"))
+		switch {
+		case strings.Contains(contentType, "json-patch"):
+		case schemaType == "array":
+			issues = append(issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintUnsupportedSchema,
+				Line:     schema.Line,
+				Location: mediaLocation,
+				Message:  "array request body has no configuration entry, the body generated from it is sent without overrides",
+			})
+		default:
+			issues = append(issues, types.LintIssue{
+				Severity: types.LintWarning,
+				Rule:     lintUnsupportedSchema,
+				Line:     schema.Line,
+				Location: mediaLocation,
+				Message:  "inline request body schema has no configuration entry, the body generated from it is sent without overrides",
+			})
+		}
+	}
+
+	if !hasJSON && len(issues) > 0 {
+		issues[len(issues)-1].Message += " (the request body of this operation is dropped)"
+	}
+
+	return issues
+}
+
+// document loads and caches a YAML or JSON document of the bundle
+func (l *bundleLinter) document(filePath string) (*yaml.Node, error) {
+	if doc, exists := l.documents[filePath]; exists {
+		return doc, nil
+	}
+	if err, exists := l.loadErrs[filePath]; exists {
+		return nil, err
+	}
+
+	data, err := fs.ReadFile(l.fsys, filePath)
+	if err != nil {
+		l.loadErrs[filePath] = err
+		return nil, err
+	}
+
+	root, err := decodeDocument(filePath, data)
+	if err != nil {
+		l.loadErrs[filePath] = err
+		return nil, err
+	}
+
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	l.documents[filePath] = root
+	return root, nil
+}
+
+// resolveRef resolves a $ref relative to the file it appears in
+func (l *bundleLinter) resolveRef(filePath, ref string) (*yaml.Node, error) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	targetPath := filePath
+	if target != "" {
+		targetPath = path.Join(path.Dir(filePath), target)
+	}
+
+	doc, err := l.document(targetPath)
+	if err != nil {
+		if target != "" {
+			return nil, fmt.Errorf("file '%s' not available", target)
+		}
+		return nil, err
+	}
+
+	node := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, token)
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(token); err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("'%s' not found", pointer)
+		}
+		node = next
+	}
+
+	return node, nil
+}
+
+// walkRefs calls fn for every $ref value below node with its JSON pointer
+func walkRefs(node *yaml.Node, pointer string, fn func(ref string, node *yaml.Node, pointer string)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				fn(value.Value, value, pointer)
+				continue
+			}
+			walkRefs(value, jsonPointer(pointer, key.Value), fn)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkRefs(item, jsonPointer(pointer, strconv.Itoa(i)), fn)
+		}
+	}
+}
+
+// mappingValue returns the value node of key in a mapping node, nil if absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKeys returns the keys of a mapping node in document order
+func mappingKeys(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	return keys
+}
+
+// scalarValue returns the value of a scalar node, empty for other nodes
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// nodeLine returns the line of a node, 0 if absent
+func nodeLine(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
+
+// jsonPointer appends escaped tokens to a JSON pointer
+func jsonPointer(base string, tokens ...string) string {
+	for _, token := range tokens {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+		base += "/" + token
+	}
+	return base
+}
+
+// sortLintIssues orders issues by line, errors first on the same line
+func sortLintIssues(issues []types.LintIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Severity == types.LintError && issues[j].Severity != types.LintError
+	})
+}
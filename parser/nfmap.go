@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// specFilePattern matches the 3GPP file naming convention TS29xxx_N<nf>_<Service>
+var specFilePattern = regexp.MustCompile(`^TS\d+_N([A-Za-z0-9-]+)_`)
+
+// builtinNFTokens maps NF tokens whose NF name is not simply the upper-cased token
+var builtinNFTokens = map[string]string{
+	"5g-eir": "5G-EIR",
+}
+
+// nfMapping holds the user overrides from nf_mapping.yaml
+var nfMapping types.NFMapping
+
+// SetNFMapping installs user overrides for NF and service mapping
+func SetNFMapping(mapping types.NFMapping) {
+	nfMapping = mapping
+}
+
+// nfMappingFingerprint identifies the installed overrides, so cached parse results
+// are rebuilt when nf_mapping.yaml changes
+func nfMappingFingerprint() string {
+	data, _ := json.Marshal(nfMapping) // Map keys are sorted by encoding/json
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// extractNFName resolves the NF of a specification from, in order: the file override,
+// the TS29xxx_N<nf>_<Service> file name, the n<nf>-<service> API name of the server URL
+// and the service-level OAuth2 scope
+func extractNFName(spec *types.OpenAPISpec, specFile string) string {
+	stem := specFileStem(specFile)
+	if nf := lookupFold(nfMapping.Files, stem); nf != "" {
+		return strings.ToUpper(nf)
+	}
+
+	if match := specFilePattern.FindStringSubmatch(stem); match != nil {
+		return nfFromToken(match[1])
+	}
+
+	if nf := nfFromAPIName(parseServerInfo(spec).APIName); nf != "" {
+		return nf
+	}
+
+	if nf := nfFromAPIName(serviceScope(spec)); nf != "" {
+		return nf
+	}
+
+	return "UNKNOWN"
+}
+
+// nfFromAPIName extracts the NF from a 3GPP API name such as nudm-sdm or n5g-eir-eic
+func nfFromAPIName(apiName string) string {
+	apiName = strings.ToLower(apiName)
+	if len(apiName) < 2 || apiName[0] != 'n' {
+		return ""
+	}
+	rest := apiName[1:]
+
+	// Tokens containing '-' (5g-eir) must be matched before splitting at the first '-'
+	for _, tokens := range []map[string]string{nfMapping.NFs, builtinNFTokens} {
+		for token := range tokens {
+			if strings.Contains(token, "-") && strings.HasPrefix(rest, strings.ToLower(token)+"-") {
+				return nfFromToken(token)
+			}
+		}
+	}
+
+	token, _, found := strings.Cut(rest, "-")
+	if !found || token == "" {
+		return ""
+	}
+	return nfFromToken(token)
+}
+
+// nfFromToken maps an NF token (udm, nwdaf, 5g-eir) to its NF name
+func nfFromToken(token string) string {
+	if nf := lookupFold(nfMapping.NFs, token); nf != "" {
+		return strings.ToUpper(nf)
+	}
+	if nf := lookupFold(builtinNFTokens, token); nf != "" {
+		return nf
+	}
+	return strings.ToUpper(token)
+}
+
+// lookupFold looks up a key case-insensitively
+func lookupFold(m map[string]string, key string) string {
+	if value, exists := m[key]; exists {
+		return value
+	}
+	for k, value := range m {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
+}
@@ -2,129 +2,277 @@ package parser
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/devuk0204/ctrlbench/types"
-
-	"gopkg.in/yaml.v3"
 )
 
-// ParseOpenAPIDir parses OpenAPI YAML files and returns service metadata
+// ParseOpenAPIDir parses OpenAPI YAML/JSON files and returns service metadata
 func ParseOpenAPIDir(dirPath string) (map[string]types.ServiceMetadata, error) {
+	return ParseOpenAPIFS(os.DirFS(dirPath), ".", "")
+}
+
+// ParseOpenAPIFS parses OpenAPI YAML/JSON files of one directory in fsys and tags
+// the resulting services with release (empty for an unversioned bundle)
+func ParseOpenAPIFS(fsys fs.FS, dir, release string) (map[string]types.ServiceMetadata, error) {
 	services := make(map[string]types.ServiceMetadata)
+	generator := newBodyGenerator(fsys)
 
-	files, err := ioutil.ReadDir(dirPath)
+	files, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read openapi dir: %w", err)
 	}
 
 	for _, fi := range files {
-		if !isYAMLFile(fi.Name()) || fi.IsDir() {
+		if !isSpecFile(fi.Name()) || fi.IsDir() {
 			continue
 		}
 
-		spec, err := loadOpenAPISpec(filepath.Join(dirPath, fi.Name()))
+		spec, err := loadOpenAPISpec(fsys, path.Join(dir, fi.Name()))
 		if err != nil {
 			fmt.Printf("⚠️  Failed to parse %s: %v\n", fi.Name(), err)
 			continue
 		}
 
-		processOpenAPISpec(spec, services)
+		// Skip generated files such as api_list.yaml
+		if spec.OpenAPI == "" {
+			continue
+		}
+
+		serviceName := processOpenAPISpec(spec, fi.Name(), release, services)
+		generator.synthesizeRequestBodies(path.Join(dir, fi.Name()), services[serviceName])
+		generator.resolveConstraints(path.Join(dir, fi.Name()), services[serviceName])
 	}
 
 	return services, nil
 }
 
-// isYAMLFile checks if file has YAML extension
-func isYAMLFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return ext == ".yaml" || ext == ".yml"
-}
-
 // loadOpenAPISpec loads and parses OpenAPI spec from file
-func loadOpenAPISpec(filePath string) (*types.OpenAPISpec, error) {
-	data, err := ioutil.ReadFile(filePath)
+func loadOpenAPISpec(fsys fs.FS, filePath string) (*types.OpenAPISpec, error) {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decodeDocument(filePath, data)
 	if err != nil {
 		return nil, err
 	}
 
 	var spec types.OpenAPISpec
-	if err := yaml.Unmarshal(data, &spec); err != nil {
+	if err := doc.Decode(&spec); err != nil {
 		return nil, err
 	}
 
 	return &spec, nil
 }
 
-// processOpenAPISpec processes a single OpenAPI spec
-func processOpenAPISpec(spec *types.OpenAPISpec, services map[string]types.ServiceMetadata) {
-	nfName := extractNFName(spec)
-	serviceName := cleanServiceName(extractServiceName(spec))
+// processOpenAPISpec processes a single OpenAPI spec and returns the name its service is registered under
+func processOpenAPISpec(spec *types.OpenAPISpec, specFile, release string, services map[string]types.ServiceMetadata) string {
+	nfName := extractNFName(spec, specFile)
+	serviceName := cleanServiceName(extractServiceName(spec, specFile))
 
-	service := getOrCreateService(services, serviceName, nfName, spec)
+	// Keep services from different spec files apart instead of merging them
+	if existing, exists := services[serviceName]; exists {
+		qualifiedName := fmt.Sprintf("%s@%s", serviceName, specFileStem(specFile))
+		fmt.Printf("⚠️  Service name collision: '%s' is defined by %s and %s, registering the latter as '%s'\n",
+			serviceName, existing.SpecFile, specFile, qualifiedName)
+		serviceName = qualifiedName
+	}
+
+	service := createService(serviceName, nfName, specFile, release, spec)
 
 	// Extract schemas for request body resolution
 	schemas := extractSchemas(spec)
 
-	// Process all paths and operations
-	for path, pathItem := range spec.Paths {
-		processPathItem(path, pathItem, service, schemas)
+	// Process all paths and operations in a fixed order so collision handling is deterministic
+	for _, path := range sortedKeys(spec.Paths) {
+		processPathItem(path, spec.Paths[path], service, spec, schemas)
 	}
 
 	services[serviceName] = *service
+	return serviceName
 }
 
-// getOrCreateService gets existing service or creates new one
-func getOrCreateService(services map[string]types.ServiceMetadata, serviceName, nfName string, spec *types.OpenAPISpec) *types.ServiceMetadata {
-	if service, exists := services[serviceName]; exists {
-		service.OpenAPISpec = spec
-		return &service
-	}
-
+// createService creates service metadata for a spec file
+func createService(serviceName, nfName, specFile, release string, spec *types.OpenAPISpec) *types.ServiceMetadata {
 	return &types.ServiceMetadata{
 		Name:        serviceName,
 		Description: extractServiceDescription(spec),
 		APIs:        make(map[string]types.APIMetadata),
 		NF:          nfName,
+		SpecFile:    specFile,
+		Release:     release,
+		Server:      parseServerInfo(spec),
 		OpenAPISpec: spec,
 	}
 }
 
+// specFileStem returns the spec file name without extension
+func specFileStem(specFile string) string {
+	return strings.TrimSuffix(specFile, filepath.Ext(specFile))
+}
+
+// parseServerInfo derives the service base path from servers[].url and its variables.
+// A leading root variable ({apiRoot}, {nrfApiRoot}) is replaced by the discovered NF URL
+// at run time, other variables are substituted with their defaults.
+func parseServerInfo(spec *types.OpenAPISpec) types.ServerInfo {
+	info := types.ServerInfo{
+		Version:     extractVersionInURI(spec.Info.Version),
+		FullVersion: spec.Info.Version,
+	}
+
+	if len(spec.Servers) == 0 {
+		return info
+	}
+
+	server := spec.Servers[0]
+	serverURL := strings.TrimSpace(server.Url)
+
+	if strings.HasPrefix(serverURL, "{") {
+		if idx := strings.Index(serverURL, "}"); idx != -1 {
+			info.APIRoot = serverURL[1:idx]
+			serverURL = serverURL[idx+1:]
+		}
+	}
+
+	for name, variable := range server.Variables {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+	}
+
+	// Absolute URLs without a root variable: keep only the path component
+	if idx := strings.Index(serverURL, "://"); idx != -1 {
+		serverURL = serverURL[idx+3:]
+		if slash := strings.Index(serverURL, "/"); slash != -1 {
+			serverURL = serverURL[slash:]
+		} else {
+			serverURL = ""
+		}
+	}
+
+	segments := strings.Split(strings.Trim(serverURL, "/"), "/")
+	if len(segments) == 1 && segments[0] == "" {
+		return info
+	}
+
+	info.BasePath = "/" + strings.Join(segments, "/")
+
+	last := segments[len(segments)-1]
+	if last == info.Version && len(segments) >= 2 {
+		info.APIName = segments[len(segments)-2]
+	} else if last != info.Version {
+		info.APIName = last
+	}
+
+	return info
+}
+
+// extractVersionInURI returns the apiVersion URI segment ("v" + major version) for info.version
+func extractVersionInURI(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return ""
+	}
+
+	major := strings.SplitN(version, ".", 2)[0]
+	return "v" + major
+}
+
+// httpMethods lists operation methods in the order they are processed
+var httpMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+
 // processPathItem processes a single path item with all operations
-func processPathItem(path string, pathItem types.PathItem, service *types.ServiceMetadata, schemas map[string]interface{}) {
+func processPathItem(path string, pathItem types.PathItem, service *types.ServiceMetadata, spec *types.OpenAPISpec, schemas map[string]interface{}) {
 	operations := map[string]*types.Operation{
 		"GET": pathItem.Get, "POST": pathItem.Post, "PUT": pathItem.Put,
 		"DELETE": pathItem.Delete, "PATCH": pathItem.Patch,
 		"HEAD": pathItem.Head, "OPTIONS": pathItem.Options,
 	}
 
-	for method, operation := range operations {
-		if operation != nil {
-			apiMetadata := createAPIMetadata(path, method, operation, schemas)
-			service.APIs[apiMetadata.Name] = apiMetadata
+	for _, method := range httpMethods {
+		operation := operations[method]
+		if operation == nil {
+			continue
+		}
+
+		apiMetadata := createAPIMetadata(path, method, operation, spec, schemas)
+
+		// Same operationId and method on another path: qualify with the path
+		if existing, exists := service.APIs[apiMetadata.Name]; exists {
+			qualifiedName := fmt.Sprintf("%s %s [%s]", getAPIName(operation, method, path), path, method)
+			fmt.Printf("⚠️  API name collision in %s: '%s' is used by %s and %s, registering the latter as '%s'\n",
+				service.SpecFile, apiMetadata.Name, existing.Path, path, qualifiedName)
+			apiMetadata.Name = qualifiedName
 		}
+
+		apiMetadata.Key = fmt.Sprintf("%s/%s/%s", specFileStem(service.SpecFile), service.Name, apiMetadata.Name)
+		service.APIs[apiMetadata.Name] = apiMetadata
 	}
 }
 
 // createAPIMetadata creates API metadata from operation
-func createAPIMetadata(path, method string, operation *types.Operation, schemas map[string]interface{}) types.APIMetadata {
+func createAPIMetadata(path, method string, operation *types.Operation, spec *types.OpenAPISpec, schemas map[string]interface{}) types.APIMetadata {
 	apiName := getAPIName(operation, method, path)
 	requestBodyType, requestBodySchema := extractRequestBodyInfo(operation, schemas)
 
 	return types.APIMetadata{
-		Name:              fmt.Sprintf("%s [%s]", apiName, method),
-		Description:       getDescription(operation),
-		Methods:           []string{method},
-		Path:              path,
-		Parameters:        extractAllParameters(path, operation),
-		RequestBody:       requestBodyType,
-		RequestBodySchema: requestBodySchema,
+		Name:                fmt.Sprintf("%s [%s]", apiName, method),
+		Description:         getDescription(operation),
+		Methods:             []string{method},
+		Path:                path,
+		Parameters:          extractAllParameters(path, operation),
+		RequestBody:         requestBodyType,
+		RequestBodySchema:   requestBodySchema,
+		RequestContentTypes: requestContentTypes(operation),
+		RequestParts:        extractRequestParts(operation),
+		Callbacks:           extractCallbacks(operation),
+		Security:            extractSecurityRequirements(spec, operation),
 	}
 }
 
+// extractSecurityRequirements resolves the effective security requirements of an operation.
+// Operation-level security overrides the document-level default.
+func extractSecurityRequirements(spec *types.OpenAPISpec, operation *types.Operation) []types.SecurityRequirement {
+	requirements := spec.Security
+	if operation.Security != nil {
+		requirements = operation.Security
+	}
+
+	var result []types.SecurityRequirement
+	for _, alternative := range requirements {
+		if len(alternative) == 0 {
+			result = append(result, types.SecurityRequirement{})
+			continue
+		}
+
+		for _, schemeName := range sortedKeys(alternative) {
+			result = append(result, types.SecurityRequirement{
+				Scheme: schemeName,
+				Type:   getSecuritySchemeType(spec, schemeName),
+				Scopes: alternative[schemeName],
+			})
+		}
+	}
+
+	return result
+}
+
+// getSecuritySchemeType returns the type of a scheme declared in components.securitySchemes
+func getSecuritySchemeType(spec *types.OpenAPISpec, schemeName string) string {
+	if spec.Components != nil {
+		if scheme, exists := spec.Components.SecuritySchemes[schemeName]; exists {
+			return scheme.Type
+		}
+	}
+	return ""
+}
+
 // extractSchemas extracts all schemas from OpenAPI components
 func extractSchemas(spec *types.OpenAPISpec) map[string]interface{} {
 	schemas := make(map[string]interface{})
@@ -133,6 +281,15 @@ func extractSchemas(spec *types.OpenAPISpec) map[string]interface{} {
 		for name, schema := range spec.Components.Schemas {
 			schemas[name] = convertSchemaToMap(schema)
 		}
+
+		// OpenAPI 3.1 $defs nested in component schemas are referenced by name as well
+		for _, schema := range spec.Components.Schemas {
+			for name, def := range schema.Defs {
+				if _, exists := schemas[name]; !exists {
+					schemas[name] = convertSchemaToMap(def)
+				}
+			}
+		}
 	}
 
 	return schemas
@@ -148,6 +305,9 @@ func convertSchemaToMap(schema types.SchemaDefinition) map[string]interface{} {
 	if schema.Type != "" {
 		result["type"] = schema.Type
 	}
+	if schema.Nullable {
+		result["nullable"] = true
+	}
 	if len(schema.Properties) > 0 {
 		result["properties"] = schema.Properties
 	}
@@ -167,17 +327,166 @@ func extractRequestBodyInfo(operation *types.Operation, schemas map[string]inter
 		return "", nil
 	}
 
-	for contentType, mediaType := range operation.RequestBody.Content {
+	for _, contentType := range requestContentTypes(operation) {
+		mediaType := operation.RequestBody.Content[contentType]
 		if strings.Contains(contentType, "json") {
-			return determineRequestBodyType(mediaType, operation, schemas)
+			return determineRequestBodyType(contentType, mediaType, schemas)
+		}
+		// Multipart bodies are configured through their JSON part
+		if part := multipartJSONPart(contentType, mediaType); part != "" {
+			if ref, _ := mediaType.Schema.Properties[part].(map[string]interface{})["$ref"].(string); ref != "" {
+				return determineRequestBodyType(contentType, types.MediaType{Schema: types.Schema{Ref: ref}}, schemas)
+			}
 		}
 	}
 
 	return "", nil
 }
 
+// requestContentTypes returns the declared request media types, preferred first:
+// application/json, other JSON types, multipart/related, then the rest
+func requestContentTypes(operation *types.Operation) []string {
+	if operation == nil || operation.RequestBody == nil {
+		return nil
+	}
+
+	rank := func(contentType string) int {
+		switch {
+		case contentType == "application/json":
+			return 0
+		case strings.Contains(contentType, "json"):
+			return 1
+		case contentType == "multipart/related":
+			return 2
+		}
+		return 3
+	}
+
+	contentTypes := sortedKeys(operation.RequestBody.Content)
+	sort.SliceStable(contentTypes, func(i, j int) bool {
+		return rank(contentTypes[i]) < rank(contentTypes[j])
+	})
+	return contentTypes
+}
+
+// multipartJSONPart returns the part of a multipart/related body holding the JSON data:
+// the part encoded as application/json, otherwise the first part referencing a schema
+func multipartJSONPart(contentType string, mediaType types.MediaType) string {
+	if contentType != "multipart/related" {
+		return ""
+	}
+
+	parts := sortedKeys(mediaType.Schema.Properties)
+	for _, part := range parts {
+		if mediaType.Encoding[part].ContentType == "application/json" {
+			return part
+		}
+	}
+	for _, part := range parts {
+		if property, ok := mediaType.Schema.Properties[part].(map[string]interface{}); ok && property["$ref"] != nil {
+			return part
+		}
+	}
+	return ""
+}
+
+// extractRequestParts lists the parts of a multipart/related request body, the JSON part first
+func extractRequestParts(operation *types.Operation) []types.BodyPart {
+	if operation == nil || operation.RequestBody == nil {
+		return nil
+	}
+	mediaType, exists := operation.RequestBody.Content["multipart/related"]
+	if !exists {
+		return nil
+	}
+
+	jsonPart := multipartJSONPart("multipart/related", mediaType)
+	var parts []types.BodyPart
+	for _, name := range sortedKeys(mediaType.Schema.Properties) {
+		property, _ := mediaType.Schema.Properties[name].(map[string]interface{})
+		part := types.BodyPart{
+			Name:        name,
+			ContentType: mediaType.Encoding[name].ContentType,
+			Binary:      property["format"] == "binary",
+		}
+		if part.ContentType == "" {
+			part.ContentType = "application/json"
+			if part.Binary {
+				part.ContentType = "application/octet-stream"
+			}
+		}
+
+		if name == jsonPart {
+			parts = append([]types.BodyPart{part}, parts...)
+		} else {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// extractCallbacks extracts notification endpoints declared in operation callbacks
+func extractCallbacks(operation *types.Operation) []types.CallbackMetadata {
+	if operation == nil || len(operation.Callbacks) == 0 {
+		return nil
+	}
+
+	var callbacks []types.CallbackMetadata
+
+	for _, name := range sortedKeys(operation.Callbacks) {
+		callback := operation.Callbacks[name]
+		for _, expression := range sortedKeys(callback) {
+			pathItem := callback[expression]
+			operations := map[string]*types.Operation{
+				"GET": pathItem.Get, "POST": pathItem.Post, "PUT": pathItem.Put,
+				"DELETE": pathItem.Delete, "PATCH": pathItem.Patch,
+				"HEAD": pathItem.Head, "OPTIONS": pathItem.Options,
+			}
+
+			for _, method := range sortedKeys(operations) {
+				notifyOp := operations[method]
+				if notifyOp == nil {
+					continue
+				}
+
+				callbacks = append(callbacks, types.CallbackMetadata{
+					Name:          name,
+					URLExpression: expression,
+					Method:        method,
+					PayloadSchema: extractCallbackPayloadSchema(notifyOp),
+					Responses:     sortedKeys(notifyOp.Responses),
+				})
+			}
+		}
+	}
+
+	return callbacks
+}
+
+// extractCallbackPayloadSchema returns the schema name of a notification payload
+func extractCallbackPayloadSchema(operation *types.Operation) string {
+	if operation.RequestBody == nil {
+		return ""
+	}
+
+	for _, contentType := range sortedKeys(operation.RequestBody.Content) {
+		mediaType := operation.RequestBody.Content[contentType]
+		if mediaType.Schema.Ref != "" {
+			return extractSchemaNameFromRef(mediaType.Schema.Ref)
+		}
+		if mediaType.Schema.Type == "array" && mediaType.Schema.Items != nil && mediaType.Schema.Items.Ref != "" {
+			return "array of " + extractSchemaNameFromRef(mediaType.Schema.Items.Ref)
+		}
+		if mediaType.Schema.Type != "" {
+			return mediaType.Schema.Type
+		}
+	}
+
+	return ""
+}
+
 // determineRequestBodyType determines request body type and schema
-func determineRequestBodyType(mediaType types.MediaType, operation *types.Operation, schemas map[string]interface{}) (string, map[string]interface{}) {
+func determineRequestBodyType(contentType string, mediaType types.MediaType, schemas map[string]interface{}) (string, map[string]interface{}) {
 	if mediaType.Schema.Ref != "" {
 		schemaName := extractSchemaNameFromRef(mediaType.Schema.Ref)
 		if schemaName != "" {
@@ -190,14 +499,15 @@ func determineRequestBodyType(mediaType types.MediaType, operation *types.Operat
 		}
 	}
 
-	if mediaType.Schema.Type == "array" {
+	// Bodies without a schema reference are named by their media type
+	if mediaType.Schema.Type == "array" || strings.Contains(contentType, "json-patch") {
 		return "patch_request", map[string]interface{}{
 			"type":        "array",
 			"description": "JSON Patch array for updates",
 		}
 	}
 
-	return inferRequestBodyType(operation), nil
+	return "default_request", nil
 }
 
 // extractAllParameters extracts all parameters from path and operation
@@ -298,166 +608,35 @@ func generateAPIName(method, path string) string {
 	}
 }
 
-// NF name extraction with comprehensive mapping
-func extractNFName(spec *types.OpenAPISpec) string {
-	candidates := []string{
-		extractNFFromTitle(spec.Info.Title),
-		extractNFFromDescription(spec.Info.Description),
-		extractNFFromServers(spec.Servers),
-		extractNFFromTags(spec.Tags),
-	}
-
-	for _, candidate := range candidates {
-		if normalized := normalizeNFName(candidate); normalized != "UNKNOWN" {
-			return normalized
-		}
-	}
-
-	return "UNKNOWN"
-}
-
-func extractNFFromTitle(title string) string {
-	title = strings.ToUpper(strings.TrimSpace(title))
-	nfPatterns := []string{"AUSF", "AMF", "SMF", "PCF", "UDR", "UDM", "NRF", "NSSF", "BSF", "CHF", "NEF", "AF", "UPF"}
-
-	for _, pattern := range nfPatterns {
-		if strings.Contains(title, pattern) {
-			return pattern
-		}
-	}
-
-	words := strings.Fields(title)
-	if len(words) > 0 && len(words[0]) <= 5 && strings.ToUpper(words[0]) == words[0] {
-		return words[0]
-	}
-
-	return ""
-}
-
-func extractNFFromDescription(desc string) string {
-	if desc == "" {
-		return ""
-	}
-
-	words := strings.Fields(strings.TrimSpace(desc))
-	if len(words) == 0 {
-		return ""
-	}
-
-	firstWord := strings.ToUpper(words[0])
-	if firstWord == "UNIFIED" && len(words) > 1 {
-		secondWord := strings.ToUpper(words[1])
-		if secondWord == "DATA" {
-			return "UDR"
-		}
-	}
-
-	return firstWord
-}
-
-func extractNFFromServers(servers []types.Server) string {
-	if len(servers) == 0 {
-		return ""
-	}
-
-	url := servers[0].Url
-	if strings.HasPrefix(url, "{") {
-		if idx := strings.Index(url, "}"); idx != -1 && len(url) > idx+1 {
-			url = url[idx+1:]
-		}
-	}
-
-	parts := strings.Split(url, "/")
-	for _, part := range parts {
-		if strings.HasPrefix(part, "n") && len(part) > 1 {
-			nfPart := strings.ToUpper(part[1:])
-			if idx := strings.Index(nfPart, "-"); idx > 0 {
-				return nfPart[:idx]
-			}
-		}
-	}
-
-	return ""
-}
-
-func extractNFFromTags(tags []types.Tag) string {
-	if len(tags) == 0 {
-		return ""
-	}
-	return strings.ToUpper(strings.TrimSpace(tags[0].Name))
-}
-
-func normalizeNFName(nfName string) string {
-	if nfName == "" {
-		return "UNKNOWN"
-	}
-
-	nfName = strings.ToUpper(strings.TrimSpace(nfName))
-
-	// Remove common suffixes
-	suffixes := []string{" API", " SERVICE", "API", "SERVICE", " FUNCTION"}
-	for _, suffix := range suffixes {
-		nfName = strings.TrimSuffix(nfName, suffix)
-	}
-	nfName = strings.TrimSpace(nfName)
-
-	// Comprehensive NF mapping
-	mapping := map[string]string{
-		"AUTHENTICATION SERVER FUNCTION": "AUSF",
-		"ACCESS AND MOBILITY MANAGEMENT": "AMF",
-		"SESSION MANAGEMENT FUNCTION":    "SMF",
-		"POLICY CONTROL FUNCTION":        "PCF",
-		"UNIFIED DATA REPOSITORY":        "UDR",
-		"UNIFIED DATA MANAGEMENT":        "UDM",
-		"NETWORK REPOSITORY FUNCTION":    "NRF",
-		"NETWORK SLICE SELECTION":        "NSSF",
-		"BINDING SUPPORT FUNCTION":       "BSF",
-		"CHARGING FUNCTION":              "CHF",
-		"NETWORK EXPOSURE FUNCTION":      "NEF",
-		"APPLICATION FUNCTION":           "AF",
-		"USER PLANE FUNCTION":            "UPF",
-	}
-
-	if canonical, exists := mapping[nfName]; exists {
-		return canonical
-	}
+// Service name extraction and cleaning
 
-	// Valid 5G NF list
-	validNFs := []string{
-		"AMF", "SMF", "UPF", "PCF", "UDM", "UDR", "AUSF", "NRF", "NSSF",
-		"NEF", "AF", "CHF", "BSF", "NWDAF", "UCMF", "UDSF", "NSSAAF",
-		"SEPP", "N3IWF", "TNGF", "W-AGF", "TWIF", "GMLC", "LMF",
-		"SMSF", "5G-EIR", "SCP", "SEAF",
+// extractServiceName resolves the service name from the override in nf_mapping.yaml,
+// the API name of the server URL (nudm-sdm), the service-level OAuth2 scope or the title
+func extractServiceName(spec *types.OpenAPISpec, specFile string) string {
+	if serviceName := lookupFold(nfMapping.Services, specFileStem(specFile)); serviceName != "" {
+		return serviceName
 	}
-
-	for _, validNF := range validNFs {
-		if nfName == validNF || strings.Contains(nfName, validNF) {
-			return validNF
-		}
+	if apiName := parseServerInfo(spec).APIName; apiName != "" {
+		return strings.ToUpper(apiName) + "Service"
 	}
-
-	return "UNKNOWN"
-}
-
-// Service name extraction and cleaning
-func extractServiceName(spec *types.OpenAPISpec) string {
-	if serviceName := extractServiceNameFromSecurity(spec); serviceName != "" {
-		return serviceName
+	if scope := serviceScope(spec); scope != "" {
+		return strings.ToUpper(scope) + "Service"
 	}
 	return extractServiceNameFromTitle(spec.Info.Title)
 }
 
-func extractServiceNameFromSecurity(spec *types.OpenAPISpec) string {
-	if len(spec.Security) == 0 {
-		return ""
-	}
-
+// serviceScope returns the service-level OAuth2 scope (e.g. nudm-sdm),
+// skipping resource-level scopes such as nudm-sdm:nssai:read
+func serviceScope(spec *types.OpenAPISpec) string {
 	for _, securityItem := range spec.Security {
-		for key, scopes := range securityItem {
-			if key == "oAuth2ClientCredentials" && len(scopes) > 0 {
-				scope := strings.ToUpper(strings.TrimSpace(scopes[0]))
-				if scope != "" {
-					return scope + "Service"
+		for _, key := range sortedKeys(securityItem) {
+			if getSecuritySchemeType(spec, key) != "oauth2" {
+				continue
+			}
+			for _, scope := range securityItem[key] {
+				scope = strings.TrimSpace(scope)
+				if scope != "" && !strings.Contains(scope, ":") {
+					return scope
 				}
 			}
 		}
@@ -520,7 +699,7 @@ func extractServiceDescription(spec *types.OpenAPISpec) string {
 
 // Schema and request body processing
 func extractSchemaNameFromRef(ref string) string {
-	if strings.Contains(ref, "#/components/schemas/") {
+	if strings.Contains(ref, "#/components/schemas/") || strings.Contains(ref, "/$defs/") {
 		parts := strings.Split(ref, "/")
 		if len(parts) > 0 {
 			return parts[len(parts)-1]
@@ -529,23 +708,14 @@ func extractSchemaNameFromRef(ref string) string {
 	return ""
 }
 
-func inferRequestBodyType(operation *types.Operation) string {
-	combined := strings.ToLower(operation.OperationID + " " + operation.Summary)
-
-	switch {
-	case strings.Contains(combined, "register") && strings.Contains(combined, "nf"):
-		return "nf_profile"
-	case strings.Contains(combined, "update") && strings.Contains(combined, "nf"):
-		return "patch_request"
-	case strings.Contains(combined, "auth"):
-		return "authentication_info"
-	case strings.Contains(combined, "subscription"):
-		return "subscription_data"
-	case strings.Contains(combined, "session") || strings.Contains(combined, "pdu"):
-		return "pdu_session_info"
-	default:
-		return "default_request"
+// sortedKeys returns map keys in sorted order for deterministic output
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
 }
 
 // GetDefaultServices returns default example services
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// releaseDirPrefix marks subdirectories holding one 3GPP release bundle (rel-16, rel-17, ...)
+const releaseDirPrefix = "rel-"
+
+// ParseOpenAPIBundles parses the spec files directly in dir as the unversioned bundle ("")
+// and every rel-* subdirectory as a named release bundle
+func ParseOpenAPIBundles(fsys fs.FS, dir string) (map[string]map[string]types.ServiceMetadata, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi dir: %w", err)
+	}
+
+	bundles := make(map[string]map[string]types.ServiceMetadata)
+
+	rootServices, err := ParseOpenAPIFS(fsys, dir, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(rootServices) > 0 {
+		bundles[""] = rootServices
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), releaseDirPrefix) {
+			continue
+		}
+
+		services, err := ParseOpenAPIFS(fsys, path.Join(dir, entry.Name()), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		bundles[entry.Name()] = services
+	}
+
+	return bundles, nil
+}
+
+// ParseOpenAPIBundlesDir parses release bundles from a directory on disk
+func ParseOpenAPIBundlesDir(dirPath string) (map[string]map[string]types.ServiceMetadata, error) {
+	return ParseOpenAPIBundles(os.DirFS(dirPath), ".")
+}
+
+// SelectReleases merges the bundles into one service map, using one release per NF.
+// NFs without a per-NF selection use the default release if it defines them,
+// otherwise the unversioned bundle, otherwise the latest release that does.
+func SelectReleases(bundles map[string]map[string]types.ServiceMetadata, selection types.ReleaseSelection) (map[string]types.ServiceMetadata, error) {
+	nfReleases := make(map[string][]string)
+	for _, release := range ReleaseNames(bundles) {
+		seen := make(map[string]bool)
+		for _, service := range bundles[release] {
+			if !seen[service.NF] {
+				nfReleases[service.NF] = append(nfReleases[service.NF], release)
+				seen[service.NF] = true
+			}
+		}
+	}
+
+	if selection.Default != "" {
+		if _, exists := bundles[selection.Default]; !exists {
+			return nil, fmt.Errorf("release bundle '%s' not found (available: %s)",
+				selection.Default, formatReleases(ReleaseNames(bundles)))
+		}
+	}
+
+	services := make(map[string]types.ServiceMetadata)
+
+	for _, nf := range sortedKeys(nfReleases) {
+		available := nfReleases[nf]
+
+		var release string
+		switch perNF := nfRelease(selection, nf); {
+		case perNF != "":
+			if !containsString(available, perNF) {
+				return nil, fmt.Errorf("release bundle '%s' has no specifications for %s (available: %s)",
+					perNF, nf, formatReleases(available))
+			}
+			release = perNF
+		case selection.Default != "" && containsString(available, selection.Default):
+			release = selection.Default
+		case available[0] == "":
+			release = ""
+		default:
+			release = available[len(available)-1]
+		}
+
+		for _, name := range sortedKeys(bundles[release]) {
+			service := bundles[release][name]
+			if service.NF != nf {
+				continue
+			}
+			if existing, exists := services[name]; exists {
+				fmt.Printf("⚠️  Service name collision: '%s' is defined for %s and %s, keeping the former\n",
+					name, existing.NF, nf)
+				continue
+			}
+			services[name] = service
+		}
+	}
+
+	return services, nil
+}
+
+// nfRelease returns the release explicitly selected for nf, if any
+func nfRelease(selection types.ReleaseSelection, nf string) string {
+	for key, release := range selection.PerNF {
+		if strings.EqualFold(key, nf) {
+			return release
+		}
+	}
+	return ""
+}
+
+// ReleaseNames returns bundle names ordered from oldest to newest release,
+// with the unversioned bundle first
+func ReleaseNames(bundles map[string]map[string]types.ServiceMetadata) []string {
+	names := sortedKeys(bundles)
+	sort.SliceStable(names, func(i, j int) bool {
+		return releaseNumber(names[i]) < releaseNumber(names[j])
+	})
+	return names
+}
+
+// releaseNumber returns the numeric part of a release name (rel-17 → 17), -1 if there is none
+func releaseNumber(release string) int {
+	number, err := strconv.Atoi(strings.TrimPrefix(release, releaseDirPrefix))
+	if err != nil {
+		return -1
+	}
+	return number
+}
+
+// formatReleases formats release names for messages
+func formatReleases(releases []string) string {
+	labels := make([]string, len(releases))
+	for i, release := range releases {
+		labels[i] = release
+		if release == "" {
+			labels[i] = "(unversioned)"
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// containsString checks if slice contains item
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,546 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devuk0204/ctrlbench/types"
+)
+
+// maxCheckDepth bounds schema nesting while a value is checked (allOf chains, recursive types)
+const maxCheckDepth = 64
+
+// RequestChecker validates prepared requests against the resolved schemas of their operations
+type RequestChecker struct {
+	g        *bodyGenerator
+	patterns map[string]*regexp.Regexp // nil when a pattern does not compile
+}
+
+// NewRequestChecker creates a checker resolving $refs in fsys
+func NewRequestChecker(fsys fs.FS) *RequestChecker {
+	return &RequestChecker{g: newBodyGenerator(fsys), patterns: make(map[string]*regexp.Regexp)}
+}
+
+// violation is one schema violation at a JSON pointer of the checked value
+type violation struct {
+	kind    string
+	pointer string
+	message string
+}
+
+// CheckRequest checks the parameters and body of a request to the operation method apiPath
+// of specPath. Body problems are named by the JSON pointer of the offending value.
+func (c *RequestChecker) CheckRequest(specPath, apiPath, method string, params []types.ParameterValue, body interface{}, contentType string) ([]types.ValidationProblem, error) {
+	doc, err := c.g.resolver.document(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", specPath, err)
+	}
+	pathItem, _ := lookupPath(doc, "paths", apiPath)
+	operation, ok := lookupPath(pathItem, strings.ToLower(method))
+	if !ok {
+		return nil, fmt.Errorf("%s %s not found in %s", method, apiPath, specPath)
+	}
+
+	problems := c.checkParameters(specPath, pathItem, operation, params)
+	return append(problems, c.checkBody(specPath, operation, body, contentType)...), nil
+}
+
+// checkParameters checks required parameters and parameter values against their schemas
+func (c *RequestChecker) checkParameters(specPath string, pathItem, operation interface{}, params []types.ParameterValue) []types.ValidationProblem {
+	values := make(map[string]interface{})
+	for _, p := range params {
+		values[p.In+"/"+strings.ToLower(p.Name)] = p.Value
+	}
+
+	// Path-level parameters first, operation parameters override them by name
+	declared := make(map[string]map[string]interface{})
+	files := make(map[string]string)
+	var order []string
+	for _, node := range []interface{}{pathItem, operation} {
+		list, _ := lookupPath(node, "parameters")
+		items, _ := list.([]interface{})
+		for _, item := range items {
+			param, paramFile := c.g.deref(item, specPath, 0)
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			if name == "" {
+				continue
+			}
+			key := in + "/" + strings.ToLower(name)
+			if _, exists := declared[key]; !exists {
+				order = append(order, key)
+			}
+			declared[key], files[key] = param, paramFile
+		}
+	}
+
+	var problems []types.ValidationProblem
+	for _, key := range order {
+		param, file := declared[key], files[key]
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+
+		value, present := values[key]
+		if !present || isEmptyParameter(value) {
+			if required, _ := param["required"].(bool); required || in == "path" {
+				problems = append(problems, types.ValidationProblem{
+					Severity: types.LintError,
+					Kind:     types.ValidationMissing,
+					Target:   "parameter",
+					Name:     name,
+					Message:  fmt.Sprintf("required %s parameter has no value", in),
+				})
+			}
+			continue
+		}
+
+		// Parameters with content (e.g. application/json) are JSON values, others are text
+		schema, coerce := param["schema"], true
+		if content, ok := param["content"].(map[string]interface{}); ok {
+			schema, _ = lookupPath(content, selectJSONContentType(content), "schema")
+			coerce = false
+
+			// JSON text is sent unchanged, it is checked as the value it encodes
+			if text, isText := value.(string); isText && json.Valid([]byte(text)) {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+					value = decoded
+				}
+			}
+		}
+		for _, v := range c.check(value, schema, file, "", coerce, 0) {
+			problems = append(problems, types.ValidationProblem{
+				Severity: types.LintError,
+				Kind:     v.kind,
+				Target:   "parameter",
+				Name:     name + v.pointer,
+				Message:  v.message,
+			})
+		}
+	}
+	return problems
+}
+
+// checkBody checks a request body against the schema of the media type it is sent as
+func (c *RequestChecker) checkBody(specPath string, operation, body interface{}, contentType string) []types.ValidationProblem {
+	requestBodyNode, ok := lookupPath(operation, "requestBody")
+	if !ok {
+		return nil
+	}
+	requestBody, file := c.g.deref(requestBodyNode, specPath, 0)
+	if body == nil {
+		if required, _ := requestBody["required"].(bool); required {
+			return []types.ValidationProblem{{
+				Severity: types.LintError,
+				Kind:     types.ValidationMissing,
+				Target:   "body",
+				Name:     "/",
+				Message:  "required request body is empty",
+			}}
+		}
+		return nil
+	}
+
+	content, _ := requestBody["content"].(map[string]interface{})
+	schemaPath := []string{contentType, "schema"}
+	if _, declared := content[contentType]; !declared || contentType == types.ContentTypeMultipart {
+		schemaPath = jsonSchemaPath(content) // The JSON part of multipart/related bodies
+	}
+	if schemaPath == nil {
+		return nil
+	}
+	schema, _ := lookupPath(content, schemaPath...)
+
+	var problems []types.ValidationProblem
+	for _, v := range c.check(body, schema, file, "", false, 0) {
+		pointer := v.pointer
+		if pointer == "" {
+			pointer = "/"
+		}
+		problems = append(problems, types.ValidationProblem{
+			Severity: types.LintError,
+			Kind:     v.kind,
+			Target:   "body",
+			Name:     pointer,
+			Message:  v.message,
+		})
+	}
+	return problems
+}
+
+// check validates value against schema. coerce accepts numbers, booleans and arrays written
+// as text, which is how path, query and header parameters arrive.
+func (c *RequestChecker) check(value, schema interface{}, file, pointer string, coerce bool, depth int) []violation {
+	m, file := c.g.deref(schema, file, 0)
+	if m == nil || depth > maxCheckDepth {
+		return nil // Unresolvable or unconstrained
+	}
+
+	var violations []violation
+	for _, member := range schemaList(m["allOf"]) {
+		violations = append(violations, c.check(value, member, file, pointer, coerce, depth+1)...)
+	}
+	if alternatives := schemaList(m["anyOf"]); len(alternatives) > 0 {
+		if matched, closest := c.matchAlternatives(value, alternatives, file, pointer, coerce, depth); matched == 0 {
+			violations = append(violations, noAlternative(closest, "anyOf")...)
+		}
+	}
+	if alternatives := schemaList(m["oneOf"]); len(alternatives) > 0 {
+		switch matched, closest := c.matchAlternatives(value, alternatives, file, pointer, coerce, depth); {
+		case matched == 0:
+			violations = append(violations, noAlternative(closest, "oneOf")...)
+		case matched > 1:
+			violations = append(violations, violation{types.ValidationInvalid, pointer,
+				fmt.Sprintf("matches %d oneOf alternatives, expected exactly one", matched)})
+		}
+	}
+	if not, ok := m["not"]; ok && len(c.check(value, not, file, pointer, coerce, depth+1)) == 0 {
+		violations = append(violations, violation{types.ValidationInvalid, pointer, "matches a schema it must not match (not)"})
+	}
+
+	if value == nil {
+		if nullable, _ := m["nullable"].(bool); nullable || schemaType(m) == "" || schemaType(m) == "null" || enumContains(m["enum"], nil) || typeListHasNull(m["type"]) {
+			return violations
+		}
+		return append(violations, violation{types.ValidationType, pointer, fmt.Sprintf("null is not allowed, expected %s", schemaType(m))})
+	}
+
+	typeName := schemaType(m)
+	value, typeOK := coerceValue(value, typeName, coerce)
+	if !typeOK {
+		return append(violations, violation{types.ValidationType, pointer,
+			fmt.Sprintf("expected %s, got %s", typeName, describeValue(value))})
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok && !enumContains(enum, value) {
+		violations = append(violations, violation{types.ValidationEnum, pointer,
+			fmt.Sprintf("'%v' is not one of %s", value, formatEnum(enum))})
+	}
+
+	switch v := value.(type) {
+	case string:
+		violations = append(violations, c.checkString(v, m, pointer)...)
+	case []interface{}:
+		violations = append(violations, checkSize(len(v), m, "minItems", "maxItems", "items", pointer)...)
+		for i, item := range v {
+			violations = append(violations, c.check(item, m["items"], file, pointer+"/"+strconv.Itoa(i), coerce, depth+1)...)
+		}
+	case map[string]interface{}:
+		violations = append(violations, c.checkObject(v, m, file, pointer, coerce, depth)...)
+	default:
+		if number, isNumber := toFloat(v); isNumber {
+			violations = append(violations, checkNumber(number, m, pointer)...)
+		}
+	}
+	return violations
+}
+
+// matchAlternatives counts the anyOf/oneOf alternatives value matches and returns the
+// violations of the closest alternative, which explain the mismatch best
+func (c *RequestChecker) matchAlternatives(value interface{}, alternatives []interface{}, file, pointer string, coerce bool, depth int) (int, []violation) {
+	matched := 0
+	var closest []violation
+	for _, alternative := range alternatives {
+		violations := c.check(value, alternative, file, pointer, coerce, depth+1)
+		if len(violations) == 0 {
+			matched++
+		} else if closest == nil || len(violations) < len(closest) {
+			closest = violations
+		}
+	}
+	return matched, closest
+}
+
+// noAlternative notes on the violations of the closest alternative that none matched
+func noAlternative(closest []violation, keyword string) []violation {
+	note := fmt.Sprintf(" (no %s alternative matches, closest shown)", keyword)
+	for i := range closest {
+		if !strings.HasSuffix(closest[i].message, " alternative matches, closest shown)") {
+			closest[i].message += note
+		}
+	}
+	return closest
+}
+
+// checkObject checks required, known and additional properties of an object
+func (c *RequestChecker) checkObject(v map[string]interface{}, m map[string]interface{}, file, pointer string, coerce bool, depth int) []violation {
+	violations := checkSize(len(v), m, "minProperties", "maxProperties", "properties", pointer)
+	for _, name := range requiredFields(m) {
+		if _, exists := v[name]; !exists {
+			violations = append(violations, violation{types.ValidationMissing, pointer + "/" + escapePointer(name), "required property is missing"})
+		}
+	}
+
+	properties, _ := m["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := pointer + "/" + escapePointer(key)
+		if property, known := properties[key]; known {
+			violations = append(violations, c.check(v[key], property, file, child, coerce, depth+1)...)
+			continue
+		}
+		switch additional := m["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, violation{types.ValidationInvalid, child, "property is not defined by the schema"})
+			}
+		case map[string]interface{}:
+			violations = append(violations, c.check(v[key], additional, file, child, coerce, depth+1)...)
+		}
+	}
+	return violations
+}
+
+// checkString checks pattern, format and length of a string
+func (c *RequestChecker) checkString(v string, m map[string]interface{}, pointer string) []violation {
+	violations := checkSize(utf8.RuneCountInString(v), m, "minLength", "maxLength", "characters", pointer)
+	if pattern, ok := m["pattern"].(string); ok {
+		if re := c.pattern(pattern); re != nil && !re.MatchString(v) {
+			violations = append(violations, violation{types.ValidationPattern, pointer,
+				fmt.Sprintf("'%s' does not match pattern %s", v, pattern)})
+		}
+	}
+	if format, ok := m["format"].(string); ok && !validFormat(format, v) {
+		violations = append(violations, violation{types.ValidationFormat, pointer,
+			fmt.Sprintf("'%s' is not a valid %s", v, format)})
+	}
+	return violations
+}
+
+// pattern compiles and caches a schema pattern, nil for patterns Go cannot compile
+func (c *RequestChecker) pattern(pattern string) *regexp.Regexp {
+	if re, exists := c.patterns[pattern]; exists {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	c.patterns[pattern] = re
+	return re
+}
+
+// checkSize checks a length or count against its minimum and maximum keywords
+func checkSize(size int, m map[string]interface{}, minKey, maxKey, unit, pointer string) []violation {
+	if minimum, ok := numberKeyword(m, minKey); ok && float64(size) < minimum {
+		return []violation{{types.ValidationRange, pointer, fmt.Sprintf("has %d %s, %s is %g", size, unit, minKey, minimum)}}
+	}
+	if maximum, ok := numberKeyword(m, maxKey); ok && float64(size) > maximum {
+		return []violation{{types.ValidationRange, pointer, fmt.Sprintf("has %d %s, %s is %g", size, unit, maxKey, maximum)}}
+	}
+	return nil
+}
+
+// checkNumber checks a number against minimum/maximum (3.0 boolean and 3.1 numeric exclusive bounds)
+func checkNumber(v float64, m map[string]interface{}, pointer string) []violation {
+	if minimum, ok := numberKeyword(m, "minimum"); ok {
+		if exclusive, _ := m["exclusiveMinimum"].(bool); v < minimum || (exclusive && v == minimum) {
+			return []violation{{types.ValidationRange, pointer, fmt.Sprintf("%g is below the minimum %g", v, minimum)}}
+		}
+	}
+	if exclusiveMinimum, ok := numberKeyword(m, "exclusiveMinimum"); ok && v <= exclusiveMinimum {
+		return []violation{{types.ValidationRange, pointer, fmt.Sprintf("%g must be greater than %g", v, exclusiveMinimum)}}
+	}
+	if maximum, ok := numberKeyword(m, "maximum"); ok {
+		if exclusive, _ := m["exclusiveMaximum"].(bool); v > maximum || (exclusive && v == maximum) {
+			return []violation{{types.ValidationRange, pointer, fmt.Sprintf("%g is above the maximum %g", v, maximum)}}
+		}
+	}
+	if exclusiveMaximum, ok := numberKeyword(m, "exclusiveMaximum"); ok && v >= exclusiveMaximum {
+		return []violation{{types.ValidationRange, pointer, fmt.Sprintf("%g must be less than %g", v, exclusiveMaximum)}}
+	}
+	return nil
+}
+
+// coerceValue checks value against a schema type. With coerce, numbers, booleans and
+// arrays written as text are converted first. An empty type accepts any value.
+func coerceValue(value interface{}, typeName string, coerce bool) (interface{}, bool) {
+	if text, isText := value.(string); isText && coerce {
+		switch typeName {
+		case "integer", "number":
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				value = number
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(text); err == nil {
+				value = b
+			}
+		case "array":
+			// Sent as the comma-separated form of the style=form/simple serialization
+			var items []interface{}
+			for _, item := range strings.Split(text, ",") {
+				items = append(items, item)
+			}
+			value = items
+		}
+	}
+
+	switch typeName {
+	case "string":
+		_, ok := value.(string)
+		return value, ok
+	case "integer":
+		number, ok := toFloat(value)
+		return value, ok && number == math.Trunc(number)
+	case "number":
+		_, ok := toFloat(value)
+		return value, ok
+	case "boolean":
+		_, ok := value.(bool)
+		return value, ok
+	case "array":
+		_, ok := value.([]interface{})
+		return value, ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return value, ok
+	}
+	return value, true
+}
+
+// toFloat converts the numeric types of decoded JSON and YAML values
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// validFormat checks the string formats used by the 3GPP specifications. Unknown formats pass.
+func validFormat(format, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(v)
+	case "ipv4":
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	case "ipv6":
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ":")
+	case "uri", "url":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "byte":
+		_, err := base64.StdEncoding.DecodeString(v)
+		return err == nil
+	}
+	return true
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// enumContains checks if value is one of the enumerated values, numbers compared by value
+func enumContains(enum interface{}, value interface{}) bool {
+	values, _ := enum.([]interface{})
+	number, isNumber := toFloat(value)
+	for _, item := range values {
+		if itemNumber, ok := toFloat(item); ok && isNumber {
+			if itemNumber == number {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeListHasNull checks for the 3.1 form type: [T, "null"]
+func typeListHasNull(t interface{}) bool {
+	list, _ := t.([]interface{})
+	for _, item := range list {
+		if item == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnum lists enumerated values for a message
+func formatEnum(enum []interface{}) string {
+	items := make([]string, 0, len(enum))
+	for _, item := range enum {
+		if item != nil {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// describeValue names the JSON type of a value for a message
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string '%s'", v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, isNumber := toFloat(value); isNumber {
+		return fmt.Sprintf("number %v", value)
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaList returns the member schemas of allOf, anyOf and oneOf
+func schemaList(node interface{}) []interface{} {
+	list, _ := node.([]interface{})
+	return list
+}
+
+// escapePointer escapes a property name as a JSON pointer token
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// isEmptyParameter checks if a parameter value is unset
+func isEmptyParameter(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+//go:build embedspecs
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Build with -tags embedspecs to ship the openapi/ bundles inside the binary.
+//
+//go:embed openapi
+var embeddedOpenAPI embed.FS
+
+// embeddedSpecs returns the embedded openapi directory
+func embeddedSpecs() fs.FS {
+	specs, err := fs.Sub(embeddedOpenAPI, "openapi")
+	if err != nil {
+		return nil
+	}
+	return specs
+}
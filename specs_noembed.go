@@ -0,0 +1,10 @@
+//go:build !embedspecs
+
+package main
+
+import "io/fs"
+
+// embeddedSpecs returns nil when the binary is built without -tags embedspecs
+func embeddedSpecs() fs.FS {
+	return nil
+}
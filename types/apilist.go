@@ -1,35 +1,90 @@
 package types
 
+import "gopkg.in/yaml.v3"
+
 // APIList represents the tree structure: NF -> Service -> API
 type APIList map[string]map[string]ServiceAPIList
 
 // ServiceAPIList represents service information with APIs
 type ServiceAPIList struct {
-	Path    string                  `yaml:"path"`
-	Version string                  `yaml:"version"`
-	APIs    map[string]APIListEntry `yaml:"apis"`
+	Path        string                  `yaml:"path"`
+	Version     string                  `yaml:"version"`
+	FullVersion string                  `yaml:"full_version,omitempty"`
+	APIRoot     string                  `yaml:"api_root,omitempty"`
+	APIName     string                  `yaml:"api_name,omitempty"`
+	SpecFile    string                  `yaml:"spec_file,omitempty"`
+	Release     string                  `yaml:"release,omitempty"`
+	APIs        map[string]APIListEntry `yaml:"apis"`
 }
 
 // APIListEntry represents an API entry in the tree structure
 type APIListEntry struct {
-	Path              string      `yaml:"path"`
-	Method            string      `yaml:"method"`
-	Parameters        []ParamMeta `yaml:"parameters"`
-	RequestBody       string      `yaml:"request_body,omitempty"`
-	RequestBodySchema BodyMeta    `yaml:"request_body_schema,omitempty"`
+	Key                string                `yaml:"key"`
+	Release            string                `yaml:"release,omitempty"`
+	SpecVersion        string                `yaml:"spec_version,omitempty"`
+	Path               string                `yaml:"path"`
+	Method             string                `yaml:"method"`
+	Parameters         []ParamMeta           `yaml:"parameters"`
+	RequestBody        string                `yaml:"request_body,omitempty"`
+	RequestBodySchema  BodyMeta              `yaml:"request_body_schema,omitempty"`
+	RequestBodyExample interface{}           `yaml:"request_body_example,omitempty"`
+	ContentTypes       []string              `yaml:"content_types,omitempty"` // Declared request media types, preferred first
+	RequestParts       []BodyPart            `yaml:"request_parts,omitempty"` // Parts of a multipart/related body
+	Scopes             ScopeSets             `yaml:"scopes,omitempty"`        // OAuth2 scopes, one set per security alternative
+	Security           []SecurityRequirement `yaml:"security,omitempty"`
 }
 
 // ParamMeta represents parameter with required information
 type ParamMeta struct {
-	Name     string `yaml:"name"`
-	Required bool   `yaml:"required"`
-	Type     string `yaml:"type,omitempty"`
-	In       string `yaml:"in,omitempty"`
+	Name        string        `yaml:"name"`
+	Required    bool          `yaml:"required"`
+	Type        string        `yaml:"type,omitempty"`
+	In          string        `yaml:"in,omitempty"`
+	Style       string        `yaml:"style,omitempty"`
+	Explode     *bool         `yaml:"explode,omitempty"`
+	ContentType string        `yaml:"content_type,omitempty"` // Set for parameters serialized with content (e.g. application/json)
+	Enum        []interface{} `yaml:"enum,omitempty"`
+	Extensible  bool          `yaml:"extensible,omitempty"` // Enum lists known values only (3GPP extensible enumeration)
 }
 
 // BodyMeta represents request body with required fields
 type BodyMeta struct {
-	SchemaName     string                 `yaml:"schema_name,omitempty"`
-	RequiredFields []string               `yaml:"required_fields,omitempty"`
-	Schema         map[string]interface{} `yaml:"schema,omitempty"`
+	SchemaName     string                     `yaml:"schema_name,omitempty"`
+	RequiredFields []string                   `yaml:"required_fields,omitempty"`
+	Schema         map[string]interface{}     `yaml:"schema,omitempty"`
+	Fields         map[string]ValueConstraint `yaml:"fields,omitempty"` // Resolved type/enum of top-level properties
+}
+
+// ScopeSets are the OAuth2 scope sets of an API, one per security alternative.
+// Any one set authorizes the API.
+type ScopeSets [][]string
+
+// MarshalYAML writes each set on one line: - [nudm-sdm, nudm-sdm:nssai:read]
+func (s ScopeSets) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, set := range s {
+		item := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, scope := range set {
+			item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: scope})
+		}
+		node.Content = append(node.Content, item)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML also reads a flat scope list, written by earlier versions, as one set
+func (s *ScopeSets) UnmarshalYAML(node *yaml.Node) error {
+	var flat []string
+	if err := node.Decode(&flat); err == nil {
+		if len(flat) > 0 {
+			*s = ScopeSets{flat}
+		}
+		return nil
+	}
+	var sets [][]string
+	if err := node.Decode(&sets); err != nil {
+		return err
+	}
+	*s = sets
+	return nil
 }
@@ -1,8 +1,21 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Configuration structure with user input sections
 type ConfigurationFile struct {
-	UserInputs UserInputSection `yaml:"user_inputs"`
+	UserInputs UserInputSection         `yaml:"user_inputs"`
+	Profiles   map[string]ConfigProfile `yaml:"profiles,omitempty"`
+}
+
+// ConfigProfile is a named environment selected with -profile. Its settings are layered
+// over user_inputs, parameters and request bodies are shared by every profile.
+type ConfigProfile struct {
+	GlobalSettings map[string]interface{}            `yaml:"global_settings,omitempty"`
+	NFSettings     map[string]map[string]interface{} `yaml:"nf_settings,omitempty"`
 }
 
 type UserInputSection struct {
@@ -40,3 +53,60 @@ type APIConfig struct {
 	UseRequestBody string                    `yaml:"use_request_body,omitempty"`
 	CustomParams   map[string]Parameter      `yaml:"custom_parameters,omitempty"`
 }
+
+// ConfigError is a problem found at a position of the configuration file
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string // Dotted key path, e.g. user_inputs.global_settings.timeout_seconds.value
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	if e.Line == 0 {
+		// Set by an override, File names its source (environment variable or -set)
+		return fmt.Sprintf("%s: %s: %s", e.File, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Message)
+}
+
+// ConfigErrors lists every problem found in the configuration file
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unique drops repeated problems at the same position, e.g. in a profile layered over user_inputs
+func (e ConfigErrors) Unique() ConfigErrors {
+	seen := make(map[string]bool)
+	var unique ConfigErrors
+	for _, err := range e {
+		key := fmt.Sprintf("%s:%d:%d: %s", err.File, err.Line, err.Column, err.Message)
+		if err.Line > 0 && seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, err)
+	}
+	return unique
+}
+
+// ConfigOverride is a value layered on top of the configuration file
+type ConfigOverride struct {
+	Path   string // Dotted key path, e.g. user_inputs.global_settings.nrf_url
+	Value  string // Parsed as YAML unless the entry is a string
+	Source string // e.g. "env CTRLBENCH_GLOBAL_NRF_URL" or "-set"
+}
+
+// ConfigValue is a configured value and where it came from
+type ConfigValue struct {
+	Path   string      `json:"path"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"` // file:line, environment variable or -set
+}
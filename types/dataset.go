@@ -0,0 +1,16 @@
+package types
+
+// Draw orders of a subscriber dataset
+const (
+	DatasetSequential = "sequential" // Rows in file order, wrapping around
+	DatasetRandom     = "random"     // Shuffled, every row once per pass
+	DatasetUniform    = "uniform"    // Independent uniform draws
+	DatasetZipf       = "zipf"       // Skewed towards the first rows ("hot subscribers")
+)
+
+// DatasetOrders lists the draw orders of a subscriber dataset
+var DatasetOrders = []string{DatasetSequential, DatasetRandom, DatasetUniform, DatasetZipf}
+
+// Subscriber is one row of a subscriber dataset: normalized column name → value
+// (supi, suci, gpsi, k, opc, snssai, sst, sd, dnn and any other column of the file)
+type Subscriber map[string]string
@@ -0,0 +1,32 @@
+package types
+
+// Lint severities
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintReport represents the result of linting an openapi directory
+type LintReport struct {
+	Files    []LintFileReport `json:"files"`
+	Errors   int              `json:"errors"`
+	Warnings int              `json:"warnings"`
+}
+
+// LintFileReport represents lint issues found in one specification file
+type LintFileReport struct {
+	File    string      `json:"file"`
+	Release string      `json:"release,omitempty"`
+	NF      string      `json:"nf,omitempty"`
+	Service string      `json:"service,omitempty"`
+	Issues  []LintIssue `json:"issues"`
+}
+
+// LintIssue represents a single lint finding
+type LintIssue struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Line     int    `json:"line,omitempty"`
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
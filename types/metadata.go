@@ -8,18 +8,72 @@ type ServiceMetadata struct {
 	Description string                 `json:"description"`
 	APIs        map[string]APIMetadata `json:"apis"`
 	NF          string                 `json:"nf"`
+	SpecFile    string                 `json:"spec_file"`
+	Release     string                 `json:"release,omitempty"`
+	Server      ServerInfo             `json:"server"`
 	OpenAPISpec *OpenAPISpec           `json:"-"`
 }
 
+// ServerInfo represents the service base derived from servers[].url and info.version
+// following the {apiRoot}/<apiName>/<apiVersion> structure of TS 29.501
+type ServerInfo struct {
+	APIRoot     string `json:"api_root,omitempty"`     // Name of the root variable (apiRoot, nrfApiRoot)
+	APIName     string `json:"api_name,omitempty"`     // e.g. nudm-sdm
+	Version     string `json:"version,omitempty"`      // apiVersion in URI, e.g. v2
+	FullVersion string `json:"full_version,omitempty"` // info.version, e.g. 2.4.0-alpha.3
+	BasePath    string `json:"base_path"`              // e.g. /nudm-sdm/v2, empty when served at the root
+}
+
 // APIMetadata represents metadata for an API with execution details
 type APIMetadata struct {
-	Name              string                 `json:"name"`
-	Description       string                 `json:"description"`
-	Methods           []string               `json:"methods"`
-	Path              string                 `json:"path"`
-	Parameters        []string               `json:"parameters"`
-	RequestBody       string                 `json:"request_body"`
-	RequestBodySchema map[string]interface{} `json:"request_body_schema,omitempty"`
+	Name                 string                     `json:"name"`
+	Key                  string                     `json:"key"` // Unique key: <spec file>/<service>/<name>
+	Description          string                     `json:"description"`
+	Methods              []string                   `json:"methods"`
+	Path                 string                     `json:"path"`
+	Parameters           []string                   `json:"parameters"`
+	RequestBody          string                     `json:"request_body"`
+	RequestBodySchema    map[string]interface{}     `json:"request_body_schema,omitempty"`
+	RequestBodyExample   interface{}                `json:"request_body_example,omitempty"`  // Body synthesized from the resolved schema
+	RequestBodyFields    map[string]ValueConstraint `json:"request_body_fields,omitempty"`   // Top-level body properties
+	RequestContentTypes  []string                   `json:"request_content_types,omitempty"` // Declared media types, preferred first
+	RequestParts         []BodyPart                 `json:"request_parts,omitempty"`         // Parts of a multipart/related body
+	ParameterConstraints map[string]ValueConstraint `json:"parameter_constraints,omitempty"`
+	Callbacks            []CallbackMetadata         `json:"callbacks,omitempty"`
+	Security             []SecurityRequirement      `json:"security,omitempty"`
+}
+
+// BodyPart is one part of a multipart/related request body, the JSON data or a binary payload
+type BodyPart struct {
+	Name        string `json:"name" yaml:"name"`
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	Binary      bool   `json:"binary,omitempty" yaml:"binary,omitempty"` // N1/N2 messages (NAS, NGAP)
+}
+
+// ValueConstraint is the resolved type and enumeration of a parameter or body field.
+// Extensible enumerations (3GPP anyOf [enum, string]) accept values outside Enum.
+type ValueConstraint struct {
+	Type       string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Enum       []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Extensible bool          `json:"extensible,omitempty" yaml:"extensible,omitempty"`
+}
+
+// SecurityRequirement represents one alternative of an operation's effective
+// security requirements. An empty Scheme means the alternative allows anonymous access.
+type SecurityRequirement struct {
+	Scheme string   `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Type   string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// CallbackMetadata represents a notification the NF sends back to the consumer
+// after an API (typically a subscription) has been invoked
+type CallbackMetadata struct {
+	Name          string   `json:"name"`
+	URLExpression string   `json:"url_expression"`
+	Method        string   `json:"method"`
+	PayloadSchema string   `json:"payload_schema,omitempty"`
+	Responses     []string `json:"responses,omitempty"`
 }
 
 // BenchmarkResult represents the result of a benchmark run
@@ -33,14 +87,68 @@ type BenchmarkResult struct {
 	MaxTime       time.Duration `json:"max_time"`
 }
 
+// InstanceStats is the benchmark result of the requests sent to one NF instance
+type InstanceStats struct {
+	InstanceID   string        `json:"nf_instance_id"`
+	URL          string        `json:"url"`
+	Requests     int           `json:"requests"`
+	SuccessCount int           `json:"success_count"`
+	FailureCount int           `json:"failure_count"`
+	TotalTime    time.Duration `json:"total_time"`
+	MinTime      time.Duration `json:"min_time"`
+	MaxTime      time.Duration `json:"max_time"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
 // APIExecutionInfo contains all information needed to execute an API call
 type APIExecutionInfo struct {
 	NF            string            `json:"nf"`
 	APIName       string            `json:"api_name"`
+	Service       string            `json:"service"`
 	Method        string            `json:"method"`
 	Path          string            `json:"path"`
 	DiscoveredURL string            `json:"discovered_url"`
-	Parameters    map[string]string `json:"parameters"`
+	Instances     []NFEndpoint      `json:"instances,omitempty"` // Discovered instances requests are spread across
+	Parameters    []ParameterValue  `json:"parameters"`
 	RequestBody   interface{}       `json:"request_body"`
+	ServicePath   string            `json:"service_path"`
 	Headers       map[string]string `json:"headers"`
+	Scopes        []string          `json:"scopes,omitempty"`     // OAuth2 scopes the token is requested for
+	ScopeSets     [][]string        `json:"scope_sets,omitempty"` // OAuth2 scope sets of the API's security alternatives
+	Policy        NFPolicy          `json:"policy"`
+	ContentType   string            `json:"content_type,omitempty"` // Media type the body is sent as
+	Parts         []RequestPart     `json:"parts,omitempty"`        // Binary parts of a multipart/related body
+	SpecFile      string            `json:"spec_file,omitempty"`    // Specification of the API, relative to the openapi directory
+}
+
+// Request media types the executor encodes
+const (
+	ContentTypeJSON       = "application/json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeJSONPatch  = "application/json-patch+json"
+	ContentTypeMultipart  = "multipart/related"
+)
+
+// RequestPart is a binary part of a multipart/related request body
+type RequestPart struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id"` // Referenced from the JSON part (e.g. n1SmMsg.contentId)
+	Data        []byte `json:"-"`
+}
+
+// ParameterValue is a typed parameter value with the serialization rules of its definition
+type ParameterValue struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Style       string      `json:"style,omitempty"`
+	Explode     *bool       `json:"explode,omitempty"`
+	ContentType string      `json:"content_type,omitempty"`
+	Value       interface{} `json:"value"`
+}
+
+// ReleaseSelection selects the 3GPP release bundle (e.g. rel-17) used for each NF
+type ReleaseSelection struct {
+	Default string            `json:"default,omitempty"`
+	PerNF   map[string]string `json:"per_nf,omitempty"`
 }
@@ -15,6 +15,10 @@ type NFProfile struct {
 	IPv4Addresses []string     `json:"ipv4Addresses,omitempty"`
 	IpEndPoints   []IpEndPoint `json:"ipEndPoints,omitempty"`
 	NFServices    []NFService  `json:"nfServices,omitempty"`
+	Locality      string       `json:"locality,omitempty"`
+	Priority      int          `json:"priority,omitempty"` // Lower values are preferred (0-65535)
+	Capacity      int          `json:"capacity,omitempty"` // Relative weight among instances of the same priority
+	Load          int          `json:"load,omitempty"`     // Current load in percent
 }
 
 type NFService struct {
@@ -27,6 +31,9 @@ type NFService struct {
 	IpEndPoints       []IpEndPoint `json:"ipEndPoints,omitempty"`
 	Port              int         `json:"port,omitempty"`
 	APIPrefix         string      `json:"apiPrefix,omitempty"`
+	Priority          int         `json:"priority,omitempty"`
+	Capacity          int         `json:"capacity,omitempty"`
+	Load              int         `json:"load,omitempty"`
 }
 
 type Version struct {
@@ -38,3 +45,12 @@ type SearchResult struct {
 	ValidityPeriod int         `json:"validityPeriod,omitempty"`
 	NFInstances    []NFProfile `json:"nfInstances"`
 }
+
+// NFEndpoint is a discovered NF instance requests are spread across
+type NFEndpoint struct {
+	InstanceID string `json:"nf_instance_id"`
+	URL        string `json:"url"`
+	Priority   int    `json:"priority,omitempty"`
+	Capacity   int    `json:"capacity,omitempty"`
+	Load       int    `json:"load,omitempty"`
+}
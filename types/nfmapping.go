@@ -0,0 +1,9 @@
+package types
+
+// NFMapping overrides how specification files are assigned to NFs and services.
+// It is read from nf_mapping.yaml, which -b creates once and never overwrites.
+type NFMapping struct {
+	NFs      map[string]string `yaml:"nfs,omitempty"`      // NF token of TS29xxx_N<nf>_* files and n<nf>-* API names → NF (e.g. 5g-eir: 5G-EIR)
+	Files    map[string]string `yaml:"files,omitempty"`    // Spec file name without extension → NF
+	Services map[string]string `yaml:"services,omitempty"` // Spec file name without extension → service name
+}
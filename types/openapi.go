@@ -1,6 +1,6 @@
 package types
 
-// OpenAPI 3.0 spec structures
+// OpenAPI 3.0/3.1 spec structures
 type OpenAPISpec struct {
 	OpenAPI    string                `yaml:"openapi"`
 	Info       Info                  `yaml:"info"`
@@ -18,9 +18,15 @@ type Info struct {
 }
 
 type Server struct {
-	Url         string                 `yaml:"url"`
-	Description string                 `yaml:"description,omitempty"`
-	Variables   map[string]interface{} `yaml:"variables,omitempty"`
+	Url         string                    `yaml:"url"`
+	Description string                    `yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `yaml:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Default     string   `yaml:"default"`
+	Enum        []string `yaml:"enum,omitempty"`
+	Description string   `yaml:"description,omitempty"`
 }
 
 type Tag struct {
@@ -29,16 +35,45 @@ type Tag struct {
 }
 
 type Components struct {
-	Schemas map[string]SchemaDefinition `yaml:"schemas,omitempty"`
+	Schemas         map[string]SchemaDefinition `yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme   `yaml:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string      `yaml:"type"`
+	Description  string      `yaml:"description,omitempty"`
+	Scheme       string      `yaml:"scheme,omitempty"`
+	BearerFormat string      `yaml:"bearerFormat,omitempty"`
+	Name         string      `yaml:"name,omitempty"`
+	In           string      `yaml:"in,omitempty"`
+	Flows        *OAuthFlows `yaml:"flows,omitempty"`
+}
+
+type OAuthFlows struct {
+	ClientCredentials *OAuthFlow `yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `yaml:"authorizationCode,omitempty"`
+	Implicit          *OAuthFlow `yaml:"implicit,omitempty"`
+	Password          *OAuthFlow `yaml:"password,omitempty"`
+}
+
+type OAuthFlow struct {
+	TokenURL         string            `yaml:"tokenUrl,omitempty"`
+	AuthorizationURL string            `yaml:"authorizationUrl,omitempty"`
+	Scopes           map[string]string `yaml:"scopes,omitempty"`
 }
 
 type SchemaDefinition struct {
-	Description string                 `yaml:"description,omitempty"`
-	Type        string                 `yaml:"type,omitempty"`
-	Properties  map[string]interface{} `yaml:"properties,omitempty"`
-	Required    []string               `yaml:"required,omitempty"`
-	Items       *SchemaDefinition      `yaml:"items,omitempty"`
-	Ref         string                 `yaml:"$ref,omitempty"`
+	Description string                      `yaml:"description,omitempty"`
+	Type        string                      `yaml:"type,omitempty"`
+	Nullable    bool                        `yaml:"nullable,omitempty"`
+	Properties  map[string]interface{}      `yaml:"properties,omitempty"`
+	Required    []string                    `yaml:"required,omitempty"`
+	Items       *SchemaDefinition           `yaml:"items,omitempty"`
+	Ref         string                      `yaml:"$ref,omitempty"`
+	Const       interface{}                 `yaml:"const,omitempty"`
+	Example     interface{}                 `yaml:"example,omitempty"`
+	Examples    []interface{}               `yaml:"examples,omitempty"`
+	Defs        map[string]SchemaDefinition `yaml:"$defs,omitempty"` // OpenAPI 3.1 local definitions
 }
 
 type PathItem struct {
@@ -60,14 +95,22 @@ type Operation struct {
 	RequestBody *RequestBody           `yaml:"requestBody,omitempty"`
 	Responses   map[string]interface{} `yaml:"responses,omitempty"`
 	Security    []map[string][]string  `yaml:"security,omitempty"`
+	Callbacks   map[string]Callback    `yaml:"callbacks,omitempty"`
 }
 
+// Callback maps a runtime URL expression (e.g. {$request.body#/callbackReference})
+// to the path item the NF will invoke on the consumer
+type Callback map[string]PathItem
+
 type Parameter struct {
-	Name        string `yaml:"name"`
-	In          string `yaml:"in"`
-	Required    bool   `yaml:"required"`
-	Description string `yaml:"description,omitempty"`
-	Schema      Schema `yaml:"schema,omitempty"`
+	Name        string               `yaml:"name"`
+	In          string               `yaml:"in"`
+	Required    bool                 `yaml:"required"`
+	Description string               `yaml:"description,omitempty"`
+	Style       string               `yaml:"style,omitempty"`
+	Explode     OptionalBool         `yaml:"explode,omitempty"`
+	Schema      Schema               `yaml:"schema,omitempty"`
+	Content     map[string]MediaType `yaml:"content,omitempty"`
 }
 
 type RequestBody struct {
@@ -77,13 +120,22 @@ type RequestBody struct {
 }
 
 type MediaType struct {
-	Schema Schema `yaml:"schema,omitempty"`
+	Schema   Schema                   `yaml:"schema,omitempty"`
+	Encoding map[string]MediaEncoding `yaml:"encoding,omitempty"` // Per part of multipart bodies
+}
+
+type MediaEncoding struct {
+	ContentType string `yaml:"contentType,omitempty"`
 }
 
 type Schema struct {
 	Type       string                 `yaml:"type,omitempty"`
 	Format     string                 `yaml:"format,omitempty"`
+	Nullable   bool                   `yaml:"nullable,omitempty"`
 	Ref        string                 `yaml:"$ref,omitempty"`
 	Properties map[string]interface{} `yaml:"properties,omitempty"`
 	Items      *Schema                `yaml:"items,omitempty"`
+	Const      interface{}            `yaml:"const,omitempty"`
+	Example    interface{}            `yaml:"example,omitempty"`
+	Examples   []interface{}          `yaml:"examples,omitempty"`
 }
@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+// NF execution policy values
+const (
+	HTTPVersionDefault = ""    // Go default: HTTP/1.1, HTTP/2 negotiated over https
+	HTTPVersion1       = "1.1" // HTTP/1.1 only
+	HTTPVersion2       = "2"   // HTTP/2, prior knowledge (h2c) over http
+
+	AuthModeNone   = "none"
+	AuthModeBearer = "bearer" // Static token from auth_token
+	AuthModeOAuth2 = "oauth2" // Client credentials token from the NRF
+
+	AuthScopesOperation = "operation" // Scope set of the security alternative with the most scopes
+	AuthScopesService   = "service"   // Scope set of the security alternative with the fewest scopes
+
+	LoadBalanceRoundRobin = "round_robin" // Discovered instances in turn
+	LoadBalanceRandom     = "random"      // A random instance per request
+	LoadBalanceWeighted   = "weighted"    // Instances of the best priority, in proportion to their capacity
+	LoadBalanceFirst      = "first"       // Only the first registered instance
+)
+
+// NFPolicy is how requests to one NF are sent, resolved from nf_settings.<NF>
+// with global_settings as fallback
+type NFPolicy struct {
+	NF          string        `json:"nf"`
+	Enabled     bool          `json:"enabled"`
+	HTTPVersion string        `json:"http_version,omitempty"`
+	BaseURL     string        `json:"base_url,omitempty"`       // Skips NF discovery when set
+	LoadBalance string        `json:"load_balancing,omitempty"` // How requests are spread across discovered instances
+	Timeout     time.Duration `json:"timeout,omitempty"`        // 0 keeps the executor timeout
+	RateLimit   float64       `json:"rate_limit_rps,omitempty"`
+	TLS         TLSPolicy     `json:"tls"`
+	Auth        AuthPolicy    `json:"auth"`
+}
+
+// TLSPolicy configures https connections to an NF
+type TLSPolicy struct {
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	CAFile             string `json:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty"` // Client certificate for mutual TLS
+	KeyFile            string `json:"key_file,omitempty"`
+}
+
+// AuthPolicy configures the Authorization header of requests to an NF
+type AuthPolicy struct {
+	Mode   string `json:"mode"`
+	Token  string `json:"-"`
+	Scopes string `json:"scopes,omitempty"` // Which security alternative tokens are requested for
+
+	// OAuth2 client credentials (TS 29.510 AccessTokenReq)
+	TokenURL              string    `json:"token_url,omitempty"`
+	RequesterNFType       string    `json:"requester_nf_type,omitempty"`
+	RequesterNFInstanceID string    `json:"requester_nf_instance_id,omitempty"`
+	NRF                   *NFPolicy `json:"-"` // Policy of the token requests
+}
@@ -0,0 +1,192 @@
+package types
+
+import "gopkg.in/yaml.v3"
+
+// OpenAPI 3.1 schemas follow JSON Schema 2020-12: "type" may be an array such as
+// [string, "null"] and "nullable" is gone. Both are folded into Type/Nullable here
+// so the rest of the tool keeps working with the 3.0 metadata model.
+
+// UnmarshalYAML decodes a schema, accepting OpenAPI 3.1 type arrays
+func (s *Schema) UnmarshalYAML(value *yaml.Node) error {
+	type plain Schema
+	node, nullable := normalizeSchemaNode(value)
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Nullable = s.Nullable || nullable
+	normalizeSchemaProperties(s.Properties)
+	return nil
+}
+
+// UnmarshalYAML decodes a component schema, accepting OpenAPI 3.1 type arrays
+func (s *SchemaDefinition) UnmarshalYAML(value *yaml.Node) error {
+	type plain SchemaDefinition
+	node, nullable := normalizeSchemaNode(value)
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Nullable = s.Nullable || nullable
+	normalizeSchemaProperties(s.Properties)
+	return nil
+}
+
+// OptionalBool is a boolean that tells "not set" apart from false, for keywords whose
+// default depends on other fields (explode defaults to true only for the form style).
+// Unlike *bool it survives the gob spec index, which drops pointers to zero values.
+type OptionalBool struct {
+	Set   bool
+	Value bool
+}
+
+// UnmarshalYAML decodes a boolean and marks it as set
+func (b *OptionalBool) UnmarshalYAML(value *yaml.Node) error {
+	if err := value.Decode(&b.Value); err != nil {
+		return err
+	}
+	b.Set = true
+	return nil
+}
+
+// Ptr returns the value as *bool, nil when not set
+func (b OptionalBool) Ptr() *bool {
+	if !b.Set {
+		return nil
+	}
+	value := b.Value
+	return &value
+}
+
+// DeclaredExample returns the value the specification suggests for this schema (const, example or examples[0])
+func (s Schema) DeclaredExample() (interface{}, bool) {
+	if s.Const != nil {
+		return s.Const, true
+	}
+	if s.Example != nil {
+		return s.Example, true
+	}
+	if len(s.Examples) > 0 {
+		return s.Examples[0], true
+	}
+	return nil, false
+}
+
+// LookupSchema finds a component schema by name, including 3.1 $defs nested in component schemas
+func (c *Components) LookupSchema(name string) (SchemaDefinition, bool) {
+	if c == nil {
+		return SchemaDefinition{}, false
+	}
+	if schema, exists := c.Schemas[name]; exists {
+		return schema, true
+	}
+	for _, schema := range c.Schemas {
+		if def, exists := schema.Defs[name]; exists {
+			return def, true
+		}
+	}
+	return SchemaDefinition{}, false
+}
+
+// normalizeSchemaNode returns a copy of a schema mapping node with a type array
+// replaced by its first non-null type, and reports whether "null" was allowed
+func normalizeSchemaNode(value *yaml.Node) (*yaml.Node, bool) {
+	if value.Kind != yaml.MappingNode {
+		return value, false
+	}
+
+	normalized := *value
+	normalized.Content = make([]*yaml.Node, 0, len(value.Content))
+	nullable := false
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+
+		switch key.Value {
+		case "type":
+			if val.Kind == yaml.SequenceNode {
+				primary := ""
+				for _, item := range val.Content {
+					if item.Value == "null" {
+						nullable = true
+					} else if primary == "" {
+						primary = item.Value
+					}
+				}
+				if primary == "" {
+					continue
+				}
+				val = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: primary, Line: val.Line, Column: val.Column}
+			}
+		case "examples":
+			// Only the 3.1 array form belongs to schemas
+			if val.Kind != yaml.SequenceNode {
+				continue
+			}
+		}
+
+		normalized.Content = append(normalized.Content, key, val)
+	}
+
+	return &normalized, nullable
+}
+
+// normalizeSchemaProperties applies normalizeSchemaMap to every property schema
+func normalizeSchemaProperties(properties map[string]interface{}) {
+	for _, prop := range properties {
+		if propMap, ok := prop.(map[string]interface{}); ok {
+			normalizeSchemaMap(propMap)
+		}
+	}
+}
+
+// normalizeSchemaMap folds 3.1 keywords of a generic schema map into their 3.0
+// equivalents: type arrays become type + nullable, const becomes a single-value
+// enum and the first of examples becomes example
+func normalizeSchemaMap(schema map[string]interface{}) {
+	if typeList, ok := schema["type"].([]interface{}); ok {
+		primary := ""
+		for _, item := range typeList {
+			if t, _ := item.(string); t == "null" {
+				schema["nullable"] = true
+			} else if primary == "" {
+				primary = t
+			}
+		}
+		if primary != "" {
+			schema["type"] = primary
+		} else {
+			delete(schema, "type")
+		}
+	}
+
+	if value, exists := schema["const"]; exists {
+		if _, hasEnum := schema["enum"]; !hasEnum {
+			schema["enum"] = []interface{}{value}
+		}
+	}
+
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		if _, hasExample := schema["example"]; !hasExample {
+			schema["example"] = examples[0]
+		}
+	}
+
+	for _, key := range []string{"items", "additionalProperties"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			normalizeSchemaMap(nested)
+		}
+	}
+	for _, key := range []string{"properties", "$defs"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			normalizeSchemaProperties(nested)
+		}
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		if list, ok := schema[key].([]interface{}); ok {
+			for _, item := range list {
+				if nested, ok := item.(map[string]interface{}); ok {
+					normalizeSchemaMap(nested)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+// Validation problem kinds
+const (
+	ValidationMissing = "missing" // Required value is empty or not configured
+	ValidationType    = "type"    // Value does not have the type of the specification
+	ValidationEnum    = "enum"    // Value is not one of the enumerated values
+	ValidationInvalid = "invalid" // Value cannot be applied (e.g. a bad override path)
+	ValidationPattern = "pattern" // String does not match the pattern of the specification
+	ValidationFormat  = "format"  // String is not in the format of the specification (date-time, uuid, ...)
+	ValidationRange   = "range"   // Length, size or value is outside the bounds of the specification
+)
+
+// ValidationReport represents the result of checking configuration.yaml against every API of api_list.yaml
+type ValidationReport struct {
+	Config      string          `json:"config"`
+	APIs        []APIValidation `json:"apis"`
+	Runnable    int             `json:"runnable"`
+	NotRunnable int             `json:"not_runnable"`
+	Disabled    int             `json:"disabled"` // APIs of NFs disabled in nf_settings, not checked
+	Warnings    int             `json:"warnings"`
+}
+
+// APIValidation represents the validation result of one API
+type APIValidation struct {
+	NF       string              `json:"nf"`
+	Service  string              `json:"service"`
+	API      string              `json:"api"`
+	Method   string              `json:"method"`
+	Runnable bool                `json:"runnable"`
+	Disabled bool                `json:"disabled,omitempty"`
+	Problems []ValidationProblem `json:"problems,omitempty"`
+}
+
+// ValidationProblem represents one configuration value an API cannot run with.
+// Errors (LintError) block execution, warnings (LintWarning) do not.
+type ValidationProblem struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Target   string `json:"target"` // parameter or body
+	Name     string `json:"name"`   // Parameter or field name, a JSON pointer into the body for pre-flight checks
+	Config   string `json:"config"` // Where to fix it in configuration.yaml
+	Message  string `json:"message"`
+}